@@ -0,0 +1,127 @@
+package terminal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CriticalPath return chain of spans, that determined total wall time
+// in chronological order, each span followed by critical chain of its child
+//
+// chain is built from span that finished last, with
+// going back to sibling that finished before it was started (sequential work),
+// parallel siblings that overlap with chain is not critical
+func (t *Terminal) CriticalPath() []*SpanSnapshot {
+	return criticalPath(t.Snapshot())
+}
+
+// SlowestSpans return top N spans by self time
+// (span duration without child spans), slowest first
+// nil is returned, when n <= 0
+func (t *Terminal) SlowestSpans(n int) []*SpanSnapshot {
+	return slowestSpans(t.Snapshot(), n)
+}
+
+func criticalPath(siblings []*SpanSnapshot) []*SpanSnapshot {
+	chain := criticalSiblings(siblings)
+	path := make([]*SpanSnapshot, 0, len(chain))
+
+	for _, span := range chain {
+		path = append(path, span)
+		path = append(path, criticalPath(span.Children)...)
+	}
+
+	return path
+}
+
+// criticalSiblings return sequential chain of siblings (in chronological order)
+// that ends with latest finished sibling
+func criticalSiblings(siblings []*SpanSnapshot) []*SpanSnapshot {
+	var last *SpanSnapshot
+
+	for _, span := range siblings {
+		if last == nil || snapshotEnd(span).After(snapshotEnd(last)) {
+			last = span
+		}
+	}
+
+	chain := make([]*SpanSnapshot, 0)
+	inChain := make(map[*SpanSnapshot]bool)
+
+	for last != nil {
+		chain = append(chain, last)
+		inChain[last] = true
+
+		// previous critical span is latest finished before current is started
+		// zero duration spans can finish right when started, so every
+		// span can be in chain only once
+		var prev *SpanSnapshot
+		for _, span := range siblings {
+			if inChain[span] || snapshotEnd(span).After(last.StartAt) {
+				continue
+			}
+
+			if prev == nil || snapshotEnd(span).After(snapshotEnd(prev)) {
+				prev = span
+			}
+		}
+
+		last = prev
+	}
+
+	// chain is collected from end to start
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	return chain
+}
+
+func slowestSpans(spans []*SpanSnapshot, n int) []*SpanSnapshot {
+	if n <= 0 {
+		return nil
+	}
+
+	all := make([]*SpanSnapshot, 0)
+
+	walkSnapshots(spans, func(_ []string, span *SpanSnapshot) {
+		all = append(all, span)
+	})
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return selfDuration(all[i]) > selfDuration(all[j])
+	})
+
+	if len(all) > n {
+		all = all[:n]
+	}
+
+	return all
+}
+
+func renderAnalysis(spans []*SpanSnapshot, slowestCount int) string {
+	spans = visibleSnapshots(spans)
+	paths := make(map[int64]string)
+	walkSnapshots(spans, func(path []string, span *SpanSnapshot) {
+		paths[span.ID] = strings.Join(path, snapshotPathDelimiter)
+	})
+
+	out := strings.Builder{}
+
+	out.WriteString(styleHeader.Render(fmt.Sprintf("critical path (%s):", renderTook(snapshotsWallTime(spans)))) + "\n")
+	for _, span := range criticalPath(spans) {
+		out.WriteString(fmt.Sprintf("%s%5s %s\n", strings.Repeat("  ", span.Depth+1), renderSnapshotDuration(span), span.Title))
+	}
+
+	if slowestCount <= 0 {
+		return out.String()
+	}
+
+	out.WriteString(styleHeader.Render(fmt.Sprintf("slowest %d spans (self time):", slowestCount)) + "\n")
+	for _, span := range slowestSpans(spans, slowestCount) {
+		out.WriteString(fmt.Sprintf("  %5s %s\n", renderTook(selfDuration(span)), paths[span.ID]))
+	}
+
+	return out.String()
+}
@@ -0,0 +1,88 @@
+package terminal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_criticalPath(t *testing.T) {
+	at := func(ms int) time.Time {
+		return time.Unix(0, 0).Add(time.Duration(ms) * time.Millisecond)
+	}
+	span := func(id int64, from, to int, children ...*SpanSnapshot) *SpanSnapshot {
+		return &SpanSnapshot{
+			ID:       id,
+			StartAt:  at(from),
+			Duration: at(to).Sub(at(from)),
+			Children: children,
+		}
+	}
+
+	// 1: build       [0 .. 100]
+	// 2:   generate  [0 .. 20]
+	// 3:   lint      [0 .. 90]  - parallel with compile, but finished earlier
+	// 4:   compile   [25 .. 100]
+	// 5:     parse   [25 .. 40]
+	// 6:     link    [40 .. 95]
+	parse := span(5, 25, 40)
+	link := span(6, 40, 95)
+	generate := span(2, 0, 20)
+	lint := span(3, 0, 90)
+	compile := span(4, 25, 100, parse, link)
+	build := span(1, 0, 100, generate, lint, compile)
+
+	assert.Equal(t,
+		[]*SpanSnapshot{build, generate, compile, parse, link},
+		criticalPath([]*SpanSnapshot{build}),
+	)
+
+	// self time: lint=90, compile=75-15-55=5, link=55, generate=20
+	assert.Equal(t,
+		[]*SpanSnapshot{lint, link, generate},
+		slowestSpans([]*SpanSnapshot{build}, 3),
+	)
+
+	assert.Len(t, slowestSpans([]*SpanSnapshot{build}, 100), 6)
+	assert.Nil(t, slowestSpans([]*SpanSnapshot{build}, 0))
+	assert.Nil(t, slowestSpans([]*SpanSnapshot{build}, -1))
+}
+
+func Test_criticalSiblings_edges(t *testing.T) {
+	at := func(ms int) time.Time {
+		return time.Unix(0, 0).Add(time.Duration(ms) * time.Millisecond)
+	}
+	span := func(id int64, from, to int) *SpanSnapshot {
+		return &SpanSnapshot{
+			ID:       id,
+			StartAt:  at(from),
+			Duration: at(to).Sub(at(from)),
+		}
+	}
+
+	// zero duration span finish right when started,
+	// so it can be previous for itself and for parallel zero span
+	zero := span(1, 10, 10)
+	zeroParallel := span(2, 10, 10)
+	before := span(3, 0, 10)
+	assert.Equal(t,
+		[]*SpanSnapshot{before, zero},
+		criticalSiblings([]*SpanSnapshot{zero, before}),
+	)
+	assert.Equal(t,
+		[]*SpanSnapshot{zeroParallel, zero},
+		criticalSiblings([]*SpanSnapshot{zero, zeroParallel}),
+	)
+
+	onlyZero := span(1, 5, 5)
+	assert.Equal(t, []*SpanSnapshot{onlyZero}, criticalSiblings([]*SpanSnapshot{onlyZero}))
+
+	// second span started exactly when first ended
+	first := span(1, 0, 10)
+	second := span(2, 10, 20)
+	assert.Equal(t,
+		[]*SpanSnapshot{first, second},
+		criticalSiblings([]*SpanSnapshot{first, second}),
+	)
+}
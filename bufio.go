@@ -2,6 +2,7 @@ package terminal
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	stdIo "io"
 	"os"
@@ -14,10 +15,10 @@ type (
 		onRestore onRestore
 		onMessage onMessage
 
-		original    *os.File
-		pipeReader  *os.File
-		pipeWriter  *os.File
-		redirecting bool
+		original   *os.File
+		pipeReader *os.File
+		pipeWriter *os.File
+		streamDone chan struct{} // closed, when all piped data is consumed
 
 		sync.RWMutex
 	}
@@ -59,29 +60,44 @@ func (io *bufIO) pipe(original *os.File) {
 	io.onPipe(io.pipeWriter)
 
 	// redirect all original data to custom stream
-	io.redirecting = true
+	io.streamDone = make(chan struct{})
 	go io.streamFrom(io.pipeReader)
 }
 
+// streamFrom read all lines until EOF, EOF is happened only
+// after pipe writer is closed and all written data is read
 func (io *bufIO) streamFrom(r stdIo.Reader) {
-	scan := bufio.NewScanner(r)
+	defer close(io.streamDone)
 
-	for io.redirecting && scan.Scan() {
-		io.onMessage(scan.Bytes())
-	}
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimSuffix(line, []byte("\n"))
+			line = bytes.TrimSuffix(line, []byte("\r"))
+
+			io.onMessage(line)
+		}
 
-	// when scan stopped
-	// possible here can be error, but we just ignore it
-	// and no more messages come to stream, it`s ok
+		if err != nil {
+			// EOF or closed pipe, no more messages come to stream
+			return
+		}
+	}
 }
 
+// restore will return original output back, and wait
+// until all already piped data is delivered to onMessage
 func (io *bufIO) restore() {
 	io.Lock()
 	defer io.Unlock()
 
-	// stop redirecting
-	io.redirecting = false
-
-	// restore pipe
+	// restore pipe, new writes will go to original output
 	io.onRestore(io.original)
+
+	// drain pipe
+	_ = io.pipeWriter.Close()
+	<-io.streamDone
+	_ = io.pipeReader.Close()
 }
@@ -12,7 +12,12 @@ type bufioMessage struct {
 	err  error
 }
 
-func bufioStdout(ctx context.Context, onMessage func(bufioMessage)) {
+// bufioStdout replace stdout with pipe and send all piped lines to
+// onMessage, until ctx is canceled. Returned channel will be closed, when
+// stdout is restored and all piped lines is delivered
+func bufioStdout(ctx context.Context, onMessage func(bufioMessage)) <-chan struct{} {
+	drained := make(chan struct{})
+
 	bufio := newBufio(
 		whenPipe(func(pipedOutput *os.File) {
 			os.Stdout = pipedOutput
@@ -21,7 +26,6 @@ func bufioStdout(ctx context.Context, onMessage func(bufioMessage)) {
 		whenRestore(func(originalOutput *os.File) {
 			os.Stdout = originalOutput
 			log.SetOutput(originalOutput)
-			onMessage(bufioMessage{err: io.EOF})
 		}),
 		whenMessage(func(message []byte) {
 			onMessage(bufioMessage{data: message})
@@ -31,9 +35,16 @@ func bufioStdout(ctx context.Context, onMessage func(bufioMessage)) {
 	// replace stdout -> buffer
 	bufio.pipe(os.Stdout)
 
-	// wait for cancel
-	<-ctx.Done()
+	go func() {
+		defer close(drained)
+
+		// wait for cancel
+		<-ctx.Done()
+
+		// replace it back
+		bufio.restore()
+		onMessage(bufioMessage{err: io.EOF})
+	}()
 
-	// replace it back
-	bufio.restore()
+	return drained
 }
@@ -0,0 +1,72 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bufIO_restore_drain(t *testing.T) {
+	const lines = 10000
+
+	original, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	require.NoError(t, err)
+	defer original.Close()
+
+	var piped *os.File
+	received := make([]string, 0, lines)
+
+	buf := newBufio(
+		whenPipe(func(pipedOutput *os.File) { piped = pipedOutput }),
+		whenRestore(func(_ *os.File) {}),
+		whenMessage(func(message []byte) { received = append(received, string(message)) }),
+	)
+
+	buf.pipe(original)
+
+	expected := make([]string, 0, lines)
+	for i := 0; i < lines; i++ {
+		expected = append(expected, fmt.Sprintf("line %d", i))
+		_, err := fmt.Fprintln(piped, expected[i])
+		require.NoError(t, err)
+	}
+
+	// last line without new line should not be lost too
+	_, err = fmt.Fprint(piped, "last")
+	require.NoError(t, err)
+	expected = append(expected, "last")
+
+	buf.restore()
+	assert.Equal(t, expected, received)
+}
+
+func TestTerminal_Release_drain(t *testing.T) {
+	const lines = 1000
+
+	output := bytes.NewBuffer(nil)
+	term := NewTerminal(WithOutput(output, fixedSize(80, 20)))
+
+	term.Capture()
+	for i := 0; i < lines; i++ {
+		fmt.Printf("captured %d\n", i)
+	}
+
+	releaseStartAt := time.Now()
+	term.Release()
+
+	assert.Less(t, time.Since(releaseStartAt), time.Millisecond*500)
+
+	// all lines is dumped after last frame
+	dump := output.String()
+	dump = dump[bytes.LastIndex(output.Bytes(), []byte("captured 0\n")):]
+
+	for i := 0; i < lines; i++ {
+		assert.Contains(t, dump, fmt.Sprintf("captured %d\n", i))
+	}
+}
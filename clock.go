@@ -0,0 +1,42 @@
+package terminal
+
+import "time"
+
+type (
+	// Clock is source of time for span timestamps and render scheduling
+	Clock interface {
+		Now() time.Time
+		NewTicker(d time.Duration) Ticker
+	}
+
+	// Ticker deliver ticks of clock at intervals
+	Ticker interface {
+		C() <-chan time.Time
+		Stop()
+	}
+
+	systemClock  struct{}
+	systemTicker struct {
+		ticker *time.Ticker
+	}
+)
+
+func newSystemClock() *systemClock {
+	return &systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return &systemTicker{ticker: time.NewTicker(d)}
+}
+
+func (t *systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t *systemTicker) Stop() {
+	t.ticker.Stop()
+}
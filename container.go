@@ -4,6 +4,7 @@ type (
 	container interface {
 		write(string)
 		content() []string
+		dropped() int // count of old lines, that not fit into container
 	}
 
 	emptyContainer struct{}
@@ -11,6 +12,7 @@ type (
 	multiLineContainer struct {
 		maxLines int
 		lines    []string
+		written  int
 	}
 )
 
@@ -36,6 +38,10 @@ func (e *emptyContainer) content() []string {
 	return nil
 }
 
+func (e *emptyContainer) dropped() int {
+	return 0
+}
+
 // ------------------
 
 func newMultiLineContainer(maxLines int) *multiLineContainer {
@@ -46,6 +52,8 @@ func newMultiLineContainer(maxLines int) *multiLineContainer {
 }
 
 func (e *multiLineContainer) write(s string) {
+	e.written++
+
 	if len(e.lines) < e.maxLines {
 		e.lines = append(e.lines, s)
 		return
@@ -59,3 +67,7 @@ func (e *multiLineContainer) write(s string) {
 func (e *multiLineContainer) content() []string {
 	return e.lines
 }
+
+func (e *multiLineContainer) dropped() int {
+	return e.written - len(e.lines)
+}
@@ -3,6 +3,7 @@ package terminal
 import "context"
 
 type ctxSpan = struct{}
+type ctxTerminal struct{}
 
 func spanFromContext(ctx context.Context) *Span {
 	if span, ok := ctx.Value(ctxSpan{}).(*Span); ok {
@@ -15,3 +16,18 @@ func spanFromContext(ctx context.Context) *Span {
 func contextWithSpan(ctx context.Context, span *Span) context.Context {
 	return context.WithValue(ctx, ctxSpan{}, span)
 }
+
+// ContextWithTerminal will bind terminal to context,
+// all spans started from this context (with StartSpan) will
+// be created in this terminal, instead of global one
+func ContextWithTerminal(ctx context.Context, t *Terminal) context.Context {
+	return context.WithValue(ctx, ctxTerminal{}, t)
+}
+
+func terminalFromContext(ctx context.Context) *Terminal {
+	if t, ok := ctx.Value(ctxTerminal{}).(*Terminal); ok {
+		return t
+	}
+
+	return nil
+}
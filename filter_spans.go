@@ -1,37 +1,101 @@
 package terminal
 
-import "sort"
+import (
+	"sort"
+	"time"
+)
 
 const (
-	spanBestA spanBest = iota
-	spanBestB
-	spanEqual
+	SpanBestA SpanBest = iota // first span is more relevant
+	SpanBestB                 // second span is more relevant
+	SpanEqual                 // spans are equal, next filter in chain will decide
+)
+
+type (
+	// SpanBest is result of spans comparison by SpanFilter
+	SpanBest int
+
+	// SpanFilter compare two spans, and decide which one is more relevant
+	// to display, when not all spans fit into render limits
+	SpanFilter func(a, b SpanInfo) SpanBest
+
+	// SpanInfo is read only state of span, used for relevance ranking
+	SpanInfo struct {
+		ID         int64
+		Title      string
+		Depth      int
+		Progress   int
+		Finished   bool
+		Failed     bool
+		StartAt    time.Time
+		ChangedAt  time.Time
+		Duration   time.Duration // time from start to end (or to now, for running spans)
+		Pinned     bool          // span or any of its descendants is pinned
+		Priority   int
+		Attributes map[string]string // should not be modified
+	}
 )
 
-type spanBest = int
-type spanFilter = func(a, b *Span) spanBest
-type spanFilters = []spanFilter
+// default relevance: finished last, newest first
+var defaultSpanRelevance = []SpanFilter{
+	FilterByFinished(),
+	FilterByNewest(),
+}
+
+// now is time of ranking, same for all spans
+// should be called under tree read lock
+func newSpanInfo(span *Span, now time.Time) SpanInfo {
+	end := span.endAt
+	if !span.finished {
+		end = now
+	}
 
-var spanRelevantFilters = spanFilters{
-	filterByFinished(),
-	filterById(),
+	return SpanInfo{
+		ID:         int64(span.id),
+		Title:      span.title,
+		Depth:      int(span.depth),
+		Progress:   span.progress,
+		Finished:   span.finished,
+		Failed:     span.err != nil,
+		StartAt:    span.startAt,
+		ChangedAt:  span.changedAt,
+		Duration:   end.Sub(span.startAt),
+		Pinned:     span.isPinned(),
+		Priority:   span.priority,
+		Attributes: span.attributes,
+	}
 }
 
-func mostRelevantSpans(spans []*Span, limit int) []*Span {
-	if len(spans) <= limit {
-		return spans
+// mostRelevantSpans will return up to limit spans, ranked by filters chain
+// hidden spans are never returned, pinned spans (and parents of pinned)
+// are always returned (even over limit),
+// and spans with higher priority are ranked before filters chain
+// result is sorted by id (start order)
+// should be called under tree read lock
+func mostRelevantSpans(spans []*Span, limit int, filters []SpanFilter) []*Span {
+	visible := visibleSpans(spans)
+	if len(visible) <= limit || len(visible) == 0 {
+		return visible
 	}
 
-	// most relevant
-	best := spans
-	sort.Slice(best, func(i, j int) bool {
-		for _, filter := range spanRelevantFilters {
-			switch filter(best[i], best[j]) {
-			case spanEqual:
+	// most relevant, sorted copy, because spans
+	// can be read concurrently by other renderers
+	ranked := make([]SpanInfo, 0, len(visible))
+	now := visible[0].now()
+	for _, span := range visible {
+		ranked = append(ranked, newSpanInfo(span, now))
+	}
+
+	filters = append([]SpanFilter{filterByPinned(), filterByPriority()}, filters...)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		for _, filter := range filters {
+			switch filter(ranked[i], ranked[j]) {
+			case SpanEqual:
 				continue
-			case spanBestA:
+			case SpanBestA:
 				return true
-			case spanBestB:
+			case SpanBestB:
 				return false
 			}
 		}
@@ -39,43 +103,142 @@ func mostRelevantSpans(spans []*Span, limit int) []*Span {
 		return false
 	})
 
-	// get most relevant
-	best = best[:limit]
+	// get most relevant, pinned is always here
+	bestIDs := make(map[int64]struct{}, limit)
+	for ind, info := range ranked {
+		if ind >= limit && !info.Pinned {
+			break
+		}
 
-	// sort by id
-	sort.Slice(best, func(i, j int) bool {
-		return best[i].id < best[j].id
-	})
+		bestIDs[info.ID] = struct{}{}
+	}
+
+	// spans is already sorted by id (start order)
+	best := make([]*Span, 0, len(bestIDs))
+	for _, span := range visible {
+		if _, exist := bestIDs[int64(span.id)]; exist {
+			best = append(best, span)
+		}
+	}
 
 	return best
 }
 
-// Finished is less relevant for display
-func filterByFinished() spanFilter {
-	return func(a, b *Span) spanBest {
-		if a.finished && !b.finished {
-			return spanBestB
+// visibleSpans return spans without hidden
+// source slice is returned, when nothing is hidden
+func visibleSpans(spans []*Span) []*Span {
+	for ind, span := range spans {
+		if !span.hidden {
+			continue
+		}
+
+		visible := append(make([]*Span, 0, len(spans)), spans[:ind]...)
+		for _, span := range spans[ind+1:] {
+			if !span.hidden {
+				visible = append(visible, span)
+			}
+		}
+
+		return visible
+	}
+
+	return spans
+}
+
+// pinned is always displayed
+func filterByPinned() SpanFilter {
+	return FilterByPredicate(func(span SpanInfo) bool {
+		return span.Pinned
+	})
+}
+
+// higher priority is more relevant
+func filterByPriority() SpanFilter {
+	return func(a, b SpanInfo) SpanBest {
+		if a.Priority > b.Priority {
+			return SpanBestA
 		}
 
-		if !a.finished && b.finished {
-			return spanBestA
+		if b.Priority > a.Priority {
+			return SpanBestB
 		}
 
-		return spanEqual
+		return SpanEqual
 	}
 }
 
-// Older is less relevant
-func filterById() spanFilter {
-	return func(a, b *Span) spanBest {
-		if a.id > b.id {
-			return spanBestA
+// FilterByFinished - finished is less relevant for display
+func FilterByFinished() SpanFilter {
+	return FilterByPredicate(func(span SpanInfo) bool {
+		return !span.Finished
+	})
+}
+
+// FilterByFailed - failed is more relevant for display
+func FilterByFailed() SpanFilter {
+	return FilterByPredicate(func(span SpanInfo) bool {
+		return span.Failed
+	})
+}
+
+// FilterByNewest - older is less relevant
+func FilterByNewest() SpanFilter {
+	return func(a, b SpanInfo) SpanBest {
+		if a.ID > b.ID {
+			return SpanBestA
+		}
+
+		if b.ID > a.ID {
+			return SpanBestB
+		}
+
+		return SpanEqual
+	}
+}
+
+// FilterByRecentlyChanged - most recently changed (logs, progress, ..) is more relevant
+func FilterByRecentlyChanged() SpanFilter {
+	return func(a, b SpanInfo) SpanBest {
+		if a.ChangedAt.After(b.ChangedAt) {
+			return SpanBestA
+		}
+
+		if b.ChangedAt.After(a.ChangedAt) {
+			return SpanBestB
+		}
+
+		return SpanEqual
+	}
+}
+
+// FilterByLongestRunning - span with longer duration is more relevant
+func FilterByLongestRunning() SpanFilter {
+	return func(a, b SpanInfo) SpanBest {
+		if a.Duration > b.Duration {
+			return SpanBestA
+		}
+
+		if b.Duration > a.Duration {
+			return SpanBestB
+		}
+
+		return SpanEqual
+	}
+}
+
+// FilterByPredicate - span that match predicate is more relevant
+func FilterByPredicate(predicate func(span SpanInfo) bool) SpanFilter {
+	return func(a, b SpanInfo) SpanBest {
+		matchA, matchB := predicate(a), predicate(b)
+
+		if matchA && !matchB {
+			return SpanBestA
 		}
 
-		if b.id > a.id {
-			return spanBestB
+		if !matchA && matchB {
+			return SpanBestB
 		}
 
-		return spanEqual
+		return SpanEqual
 	}
 }
@@ -1,7 +1,9 @@
 package terminal
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -26,7 +28,7 @@ func Test_mostRelevantSpans(t *testing.T) {
 		{
 			name:  "limited to 3",
 			limit: 3,
-			want:  []*Span{span3, span1, span4},
+			want:  []*Span{span1, span3, span4},
 		},
 		{
 			name:  "limited to 1",
@@ -35,7 +37,142 @@ func Test_mostRelevantSpans(t *testing.T) {
 		},
 	}
 	for _, tt := range tests {
-		got := mostRelevantSpans(spans, tt.limit)
+		got := mostRelevantSpans(spans, tt.limit, defaultSpanRelevance)
 		assert.Equal(t, tt.want, got)
+
+		// source is not modified
+		assert.Equal(t, []*Span{span1, span2, span3, span4}, spans)
+	}
+}
+
+func Test_mostRelevantSpans_relevance(t *testing.T) {
+	startAt := time.Unix(0, 0)
+
+	span1 := &Span{id: 1, finished: true, err: errors.New("failed"), startAt: startAt, endAt: startAt.Add(time.Second)}
+	span2 := &Span{id: 2, finished: true, startAt: startAt, endAt: startAt.Add(time.Second * 5)}
+	span3 := &Span{id: 3, finished: false, startAt: startAt, changedAt: startAt.Add(time.Second)}
+	span4 := &Span{id: 4, finished: false, startAt: startAt, changedAt: startAt.Add(time.Second * 2)}
+	spans := []*Span{span1, span2, span3, span4}
+
+	tests := []struct {
+		name      string
+		relevance []SpanFilter
+		want      []*Span
+	}{
+		{
+			name:      "failed first",
+			relevance: []SpanFilter{FilterByFailed(), FilterByFinished(), FilterByNewest()},
+			want:      []*Span{span1, span4},
+		},
+		{
+			name:      "recently changed",
+			relevance: []SpanFilter{FilterByRecentlyChanged()},
+			want:      []*Span{span3, span4},
+		},
+		{
+			name: "custom predicate",
+			relevance: []SpanFilter{FilterByPredicate(func(span SpanInfo) bool {
+				return span.ID%2 == 0
+			})},
+			want: []*Span{span2, span4},
+		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mostRelevantSpans(spans, 2, tt.relevance))
+		})
+	}
+}
+
+func Test_mostRelevantSpans_displayControl(t *testing.T) {
+	pinned1 := &Span{id: 1, finished: true, pinned: true}
+	pinned2 := &Span{id: 2, finished: true, pinned: true}
+	pinned3 := &Span{id: 3, pinned: true}
+	running := &Span{id: 4}
+	done := &Span{id: 5, finished: true}
+	important := &Span{id: 6, finished: true, priority: 10}
+	hidden := &Span{id: 7, pinned: true, hidden: true}
+
+	tests := []struct {
+		name     string
+		spans    []*Span
+		limit    int
+		want     []*Span
+		wantMore string
+	}{
+		{
+			name:     "pinned over limit",
+			spans:    []*Span{pinned1, pinned2, pinned3, running, done},
+			limit:    1,
+			want:     []*Span{pinned1, pinned2, pinned3},
+			wantMore: "  +1 more running, 1 done",
+		},
+		{
+			name:  "all pinned",
+			spans: []*Span{pinned1, pinned2, pinned3},
+			limit: 1,
+			want:  []*Span{pinned1, pinned2, pinned3},
+		},
+		{
+			name:     "priority before relevance",
+			spans:    []*Span{running, done, important},
+			limit:    1,
+			want:     []*Span{important},
+			wantMore: "  +1 more running, 1 done",
+		},
+		{
+			name:  "hidden never returned",
+			spans: []*Span{hidden, running},
+			limit: 1,
+			want:  []*Span{running},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mostRelevantSpans(tt.spans, tt.limit, defaultSpanRelevance)
+			assert.Equal(t, tt.want, got)
+
+			total, finished := 0, 0
+			for _, span := range tt.spans {
+				if span.hidden {
+					continue
+				}
+
+				total++
+				if span.finished {
+					finished++
+				}
+			}
+
+			assert.Equal(t, tt.wantMore, stripANSI(renderMoreSpans(got, total, finished)))
+		})
+	}
+}
+
+func Test_mostRelevantSpans_longestRunning(t *testing.T) {
+	now := time.Unix(100, 0)
+	clock := fixedClock(now)
+
+	span1 := &Span{id: 1, startAt: now.Add(-time.Second * 10), clock: clock}
+	span2 := &Span{id: 2, startAt: now.Add(-time.Second * 30), clock: clock}
+	span3 := &Span{id: 3, finished: true, startAt: now.Add(-time.Minute), endAt: now.Add(-time.Second * 40)}
+	spans := []*Span{span1, span2, span3}
+
+	// running spans is measured to same time
+	var durations []time.Duration
+	spy := SpanFilter(func(a, b SpanInfo) SpanBest {
+		durations = append(durations, a.Duration, b.Duration)
+		return SpanEqual
+	})
+
+	got := mostRelevantSpans(spans, 2, []SpanFilter{spy, FilterByLongestRunning()})
+	assert.Equal(t, []*Span{span2, span3}, got)
+
+	for _, d := range durations {
+		assert.Contains(t, []time.Duration{time.Second * 10, time.Second * 30, time.Second * 20}, d)
+	}
+}
+
+func Test_mostRelevantSpans_emptyLimit(t *testing.T) {
+	assert.Empty(t, mostRelevantSpans(nil, -1, defaultSpanRelevance))
 }
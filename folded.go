@@ -0,0 +1,47 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteFoldedStacks will write all spans in folded stacks format
+// one line per unique span stack `root;child;detail <self time in microseconds>`
+// output can be used with standard flamegraph tooling (flamegraph.pl, inferno, speedscope)
+func (t *Terminal) WriteFoldedStacks(w io.Writer) error {
+	stacks := make([]string, 0)
+	values := make(map[string]int64)
+
+	walkSnapshots(t.Snapshot(), func(path []string, span *SpanSnapshot) {
+		frames := make([]string, 0, len(path))
+		for _, title := range path {
+			frames = append(frames, foldedFrame(title))
+		}
+
+		stack := strings.Join(frames, ";")
+		if _, exist := values[stack]; !exist {
+			stacks = append(stacks, stack)
+		}
+
+		values[stack] += selfDuration(span).Microseconds()
+	})
+
+	for _, stack := range stacks {
+		if values[stack] <= 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %d\n", stack, values[stack]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ";" is frames delimiter and newline is stack delimiter,
+// so they can`t be used inside frame title
+func foldedFrame(title string) string {
+	return strings.NewReplacer(";", ":", "\n", " ", "\r", " ").Replace(title)
+}
@@ -0,0 +1,85 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportTestTerminal create headless terminal with same span tree
+// for all exports (folded, pprof, summary, timeline):
+//
+//	build          0s - 3s
+//	  compile      0s - 1s
+//	  test         1s - 2.5s (failed)
+//	    unit       1s - 2s
+//	deploy         3s - running
+//	  upload; retry  3.5s - running
+//
+// clock is fixed at 4s
+func newExportTestTerminal() *Terminal {
+	startAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time {
+		return startAt.Add(d)
+	}
+
+	term := NewTerminal(WithHeadless(), WithClock(fixedClock(at(time.Second*4))))
+
+	ctx, build := term.span(context.Background(), WithTitle("build"))
+	_, compile := term.span(ctx, WithTitle("compile"))
+	testCtx, test := term.span(ctx, WithTitle("test"))
+	_, unit := term.span(testCtx, WithTitle("unit"))
+	ctx, deploy := term.span(context.Background(), WithTitle("deploy"))
+	_, upload := term.span(ctx, WithTitle("upload; retry"))
+
+	unit.End()
+	test.SetError(errors.New("2 tests failed"))
+	test.End()
+	compile.End()
+	build.End()
+
+	build.startAt, build.endAt = at(0), at(time.Second*3)
+	compile.startAt, compile.endAt = at(0), at(time.Second)
+	test.startAt, test.endAt = at(time.Second), at(time.Millisecond*2500)
+	unit.startAt, unit.endAt = at(time.Second), at(time.Second*2)
+	deploy.startAt = at(time.Second * 3)
+	upload.startAt = at(time.Millisecond * 3500)
+
+	return term
+}
+
+func TestTerminal_WriteFoldedStacks(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, newExportTestTerminal().WriteFoldedStacks(buf))
+
+	assert.Equal(t, ""+
+		"build 500000\n"+
+		"build;compile 1000000\n"+
+		"build;test 500000\n"+
+		"build;test;unit 1000000\n"+
+		"deploy 500000\n"+
+		"deploy;upload: retry 500000\n",
+		buf.String(),
+	)
+}
+
+func Test_foldedFrame(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "build", want: "build"},
+		{title: "a;b", want: "a:b"},
+		{title: "multi\nline\r", want: "multi line "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			assert.Equal(t, tt.want, foldedFrame(tt.title))
+		})
+	}
+}
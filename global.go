@@ -1,9 +1,13 @@
 package terminal
 
-import "context"
+import (
+	"context"
+	"sync"
+)
 
 var globalTerminal *Terminal
 var globalTerminalInitialized = false
+var globalTerminalMux sync.RWMutex
 
 func init() {
 	globalTerminal = NewTerminal()
@@ -12,19 +16,47 @@ func init() {
 // StartSpan will extract parent Span from context (if exist) and create new Span from it
 // new Span will be written to new context
 // In case when terminal is not active, Span can be nil, but it is totally safe to use
+// Span is created in terminal bound to context (see ContextWithTerminal)
+// or in global terminal otherwise
 func StartSpan(ctx context.Context, title string, opts ...StartOpt) (context.Context, *Span) {
-	if globalTerminal == nil {
+	terminal := terminalFromContext(ctx)
+	if terminal == nil {
+		terminal = GlobalTerminal()
+	}
+
+	if terminal == nil {
 		return ctx, nil
 	}
 
-	return globalTerminal.span(ctx, append([]StartOpt{WithTitle(title)}, opts...)...)
+	return terminal.StartSpan(ctx, title, opts...)
+}
+
+// Context is root context of global terminal (see Terminal.Context)
+func Context() context.Context {
+	terminal := GlobalTerminal()
+	if terminal == nil {
+		return context.Background()
+	}
+
+	return terminal.Context()
+}
+
+// GlobalTerminal return terminal used by package level functions
+func GlobalTerminal() *Terminal {
+	globalTerminalMux.RLock()
+	defer globalTerminalMux.RUnlock()
+
+	return globalTerminal
 }
 
 // SetGlobalTerminal allow to customize terminal
 // and set it as default for span creation and output
 // method can be called only once, all other calls
-// will be ignored
+// will be ignored (use SwapGlobalTerminal for explicit replacing)
 func SetGlobalTerminal(t *Terminal) {
+	globalTerminalMux.Lock()
+	defer globalTerminalMux.Unlock()
+
 	if globalTerminalInitialized {
 		return
 	}
@@ -33,25 +65,72 @@ func SetGlobalTerminal(t *Terminal) {
 	globalTerminalInitialized = true
 }
 
+// SwapGlobalTerminal will replace global terminal and return previous one
+// previous terminal is not released automatically
+func SwapGlobalTerminal(t *Terminal) *Terminal {
+	globalTerminalMux.Lock()
+	defer globalTerminalMux.Unlock()
+
+	previous := globalTerminal
+	globalTerminal = t
+	globalTerminalInitialized = true
+
+	return previous
+}
+
+// ResetGlobalTerminal will replace global terminal with new default one
+// and allow SetGlobalTerminal to be called again (useful between tests)
+// previous terminal is not released automatically
+func ResetGlobalTerminal() {
+	globalTerminalMux.Lock()
+	defer globalTerminalMux.Unlock()
+
+	globalTerminal = NewTerminal()
+	globalTerminalInitialized = false
+}
+
 // CaptureOutput will capture control on output to stdout/stderr
 // and display custom logs from spans
 // all other print/logs will be redirected and printed in special
 // region alongside span logs
 func CaptureOutput() {
-	if globalTerminal == nil {
+	terminal := GlobalTerminal()
+	if terminal == nil {
 		return
 	}
 
-	globalTerminal.capture()
+	terminal.Capture()
 }
 
 // ReleaseOutput will stop terminal for outputting span logs
 // release stdout to default control
 // all span updates in released mode, may be ignored
 func ReleaseOutput() {
-	if globalTerminal == nil {
+	terminal := GlobalTerminal()
+	if terminal == nil {
+		return
+	}
+
+	terminal.Release()
+}
+
+// Guard will release global terminal, should be used with defer
+// instead of ReleaseOutput. When called in panic, it also print panic
+// and stack trace to stderr (after buffered stdout is restored) and re-panic
+//
+//	terminal.CaptureOutput()
+//	defer terminal.Guard()
+func Guard() {
+	recovered := recover() // recover work only when called directly in deferred function
+
+	terminal := GlobalTerminal()
+	if terminal == nil {
+		if recovered != nil {
+			panic(recovered)
+		}
+
 		return
 	}
 
-	globalTerminal.release()
+	terminal.guard(recovered)
 }
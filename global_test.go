@@ -0,0 +1,37 @@
+package terminal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwapGlobalTerminal(t *testing.T) {
+	defer ResetGlobalTerminal()
+
+	first := NewTerminal(WithHeadless())
+	second := NewTerminal(WithHeadless())
+
+	SwapGlobalTerminal(first)
+	_, span := StartSpan(context.Background(), "first")
+	assert.Len(t, first.Snapshot(), 1)
+
+	// set is ignored after explicit swap
+	SetGlobalTerminal(second)
+	assert.Same(t, first, GlobalTerminal())
+
+	previous := SwapGlobalTerminal(second)
+	assert.Same(t, first, previous)
+
+	_, secondSpan := StartSpan(context.Background(), "second")
+	assert.Len(t, first.Snapshot(), 1)
+	assert.Len(t, second.Snapshot(), 1)
+
+	// span ids is generated per terminal
+	assert.Equal(t, span.id, secondSpan.id)
+
+	ResetGlobalTerminal()
+	SetGlobalTerminal(first)
+	assert.Same(t, first, GlobalTerminal())
+}
@@ -0,0 +1,109 @@
+package terminal
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"syscall"
+)
+
+// terminal will be released, when process receive one of this signals
+var releaseSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// exit codes used when signal can not be re-raised (windows)
+var signalExitCodes = map[os.Signal]int{
+	os.Interrupt:    130,
+	syscall.SIGTERM: 143,
+}
+
+// Guard will release terminal, should be used with defer right after Capture.
+// When called in panic, it also print panic and stack trace to stderr
+// (after buffered stdout is restored) and re-panic
+// Panics in other goroutines can`t be guarded, they will kill process before
+//
+//	term.Capture()
+//	defer term.Guard()
+func (t *Terminal) Guard() {
+	t.guard(recover())
+}
+
+func (t *Terminal) guard(recovered interface{}) {
+	if recovered == nil {
+		t.Release()
+		return
+	}
+
+	stack := debug.Stack()
+	t.Release()
+
+	_, _ = fmt.Fprintf(t.realStderr, "panic: %v\n\n%s\n", recovered, stack)
+	panic(recovered)
+}
+
+// handleSignals will release terminal and re-raise signal,
+// when process is interrupted while output is captured
+// signals is handled only when enabled by WithSignalHandling,
+// interrupt is two-stage with WithGracefulInterrupt option
+// should be called under terminal lock
+func (t *Terminal) handleSignals() {
+	enabled := t.opts.signalHandling || t.opts.gracefulInterrupt
+	if !enabled || t.opts.withoutSignalHandling || t.stopSignals != nil {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+
+	signal.Notify(signals, releaseSignals...)
+	t.stopSignals = func() {
+		signal.Stop(signals)
+		close(stop)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case sig := <-signals:
+				if sig == os.Interrupt && t.isGracefulInterrupt() {
+					t.interrupt() // first Ctrl+C, wait for spans winding down
+					continue
+				}
+
+				t.Release()
+				raiseSignal(sig)
+				return
+			}
+		}
+	}()
+}
+
+// should be called under terminal lock
+func (t *Terminal) stopHandleSignals() {
+	if t.stopSignals == nil {
+		return
+	}
+
+	t.stopSignals()
+	t.stopSignals = nil
+}
+
+// raiseSignal send signal to own process again, our handler
+// is already stopped, so default handler will terminate process
+func raiseSignal(sig os.Signal) {
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(sig)
+	}
+
+	if err != nil {
+		code, exist := signalExitCodes[sig]
+		if !exist {
+			code = 1
+		}
+
+		os.Exit(code)
+	}
+}
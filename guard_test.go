@@ -0,0 +1,125 @@
+package terminal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal_Guard(t *testing.T) {
+	output := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+
+	term := NewTerminal(WithOutput(output, fixedSize(80, 20)), WithoutSignalHandling())
+	term.realStderr = stderr
+
+	assert.PanicsWithValue(t, "boom", func() {
+		term.Capture()
+		defer term.Guard()
+
+		fmt.Println("before panic")
+		panic("boom")
+	})
+
+	assert.False(t, term.isActive())
+	assert.Contains(t, output.String(), "before panic\n")
+	assert.Contains(t, stderr.String(), "panic: boom")
+	assert.Contains(t, stderr.String(), "guard_test.go")
+}
+
+func TestGuard(t *testing.T) {
+	defer ResetGlobalTerminal()
+
+	output := bytes.NewBuffer(nil)
+	SwapGlobalTerminal(NewTerminal(WithOutput(output, fixedSize(80, 20)), WithoutSignalHandling()))
+
+	func() {
+		CaptureOutput()
+		defer Guard()
+
+		fmt.Println("no panic")
+	}()
+
+	assert.False(t, GlobalTerminal().isActive())
+	assert.Contains(t, output.String(), "no panic\n")
+}
+
+func TestTerminal_interrupt(t *testing.T) {
+	term := NewTerminal(WithHeadless(), WithGracefulInterrupt(), WithoutSignalHandling())
+	assert.False(t, NewTerminal(WithHeadless()).isGracefulInterrupt(), "graceful interrupt is explicit")
+
+	ctx := term.Context()
+	assert.True(t, term.isGracefulInterrupt())
+
+	_, span := StartSpan(ctx, "bound to terminal")
+	assert.Len(t, term.Snapshot(), 1)
+
+	term.interrupt()
+
+	assert.Error(t, ctx.Err())
+	assert.False(t, term.isGracefulInterrupt(), "second interrupt should release terminal")
+
+	term.tree.read(func(_ []*Span) {
+		assert.True(t, span.cancelling())
+	})
+}
+
+func TestTerminal_interrupt_withoutCapture(t *testing.T) {
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+
+	term := NewTerminal(WithOutput(bytes.NewBuffer(nil), fixedSize(80, 20)), WithGracefulInterrupt())
+	defer term.Release()
+
+	term.mux.RLock()
+	installed := term.stopSignals != nil
+	term.mux.RUnlock()
+	require.True(t, installed, "signals are handled without capture")
+
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skipf("interrupt is not supported: %v", err)
+	}
+
+	select {
+	case <-term.Context().Done():
+	case <-time.After(time.Second * 5):
+		t.Fatal("context is not cancelled by interrupt")
+	}
+
+	assert.True(t, term.tree.isCancelling())
+}
+
+func TestTerminal_handleSignals_optIn(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []OptsInitializer
+		want bool
+	}{
+		{name: "default", want: false},
+		{name: "enabled", opts: []OptsInitializer{WithSignalHandling()}, want: true},
+		{name: "graceful interrupt", opts: []OptsInitializer{WithGracefulInterrupt()}, want: true},
+		{name: "disabled", opts: []OptsInitializer{WithSignalHandling(), WithoutSignalHandling()}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]OptsInitializer{WithOutput(bytes.NewBuffer(nil), fixedSize(80, 20))}, tt.opts...)
+			term := NewTerminal(opts...)
+
+			term.Capture()
+
+			term.mux.RLock()
+			installed := term.stopSignals != nil
+			term.mux.RUnlock()
+
+			term.Release()
+
+			assert.Equal(t, tt.want, installed)
+			assert.Nil(t, term.stopSignals, "handler is stopped on release")
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package terminal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// how many latest durations stored for each span path
+	historyMaxSamples = 20
+
+	// spans faster than this is too noisy for regression detection
+	historyMinRegressionDuration = time.Millisecond * 50
+
+	// estimated progress of running span, never reach 100%
+	historyMaxEstimatedProgress = 99
+
+	historyFileVersion = 1
+	historyDirName     = "span-terminal"
+)
+
+type (
+	historyStore struct {
+		path                string
+		regressionThreshold float64
+		records             map[string][]time.Duration
+
+		mux sync.RWMutex
+	}
+
+	historyFile struct {
+		Version int                `json:"version"`
+		Spans   map[string][]int64 `json:"spans"` // span path -> durations in ms
+	}
+)
+
+// historyFilePath is file inside user cache dir
+// for example ~/.cache/span-terminal/{name}.json
+func historyFilePath(name string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed find user cache dir: %w", err)
+	}
+
+	return filepath.Join(cacheDir, historyDirName, name+".json"), nil
+}
+
+func newHistoryStore(path string, regressionThreshold float64) *historyStore {
+	return &historyStore{
+		path:                path,
+		regressionThreshold: regressionThreshold,
+		records:             make(map[string][]time.Duration),
+	}
+}
+
+func (h *historyStore) load() error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		// first run, nothing to compare with
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed read history: %w", err)
+	}
+
+	file := historyFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed parse history '%s': %w", h.path, err)
+	}
+
+	h.records = make(map[string][]time.Duration, len(file.Spans))
+	for key, samples := range file.Spans {
+		for _, ms := range samples {
+			h.records[key] = append(h.records[key], time.Duration(ms)*time.Millisecond)
+		}
+	}
+
+	return nil
+}
+
+func (h *historyStore) save() error {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	file := historyFile{
+		Version: historyFileVersion,
+		Spans:   make(map[string][]int64, len(h.records)),
+	}
+
+	for key, samples := range h.records {
+		for _, duration := range samples {
+			file.Spans[key] = append(file.Spans[key], duration.Milliseconds())
+		}
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed encode history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("failed create history dir: %w", err)
+	}
+
+	if err := os.WriteFile(h.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed write history: %w", err)
+	}
+
+	return nil
+}
+
+// record will add durations of all successfully finished spans
+func (h *historyStore) record(spans []*SpanSnapshot) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+
+	walkSnapshots(spans, func(path []string, span *SpanSnapshot) {
+		if !span.Finished || span.Error != "" {
+			return
+		}
+
+		key := historyKey(path)
+		samples := append(h.records[key], span.Duration)
+
+		if len(samples) > historyMaxSamples {
+			samples = samples[len(samples)-historyMaxSamples:]
+		}
+
+		h.records[key] = samples
+	})
+}
+
+// median of all previously recorded durations for span path
+func (h *historyStore) median(path []string) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+
+	samples := h.records[historyKey(path)]
+	if len(samples) == 0 {
+		return 0, false
+	}
+
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2, true
+	}
+
+	return sorted[middle], true
+}
+
+// compare return duration change relative to median (0.35 = +35%)
+func (h *historyStore) compare(path []string, took time.Duration) (float64, bool) {
+	median, exist := h.median(path)
+	if !exist || median <= 0 {
+		return 0, false
+	}
+
+	return float64(took-median) / float64(median), true
+}
+
+func (h *historyStore) isRegression(took time.Duration, change float64) bool {
+	if took < historyMinRegressionDuration {
+		return false
+	}
+
+	return change > h.regressionThreshold
+}
+
+func historyKey(path []string) string {
+	return strings.Join(path, snapshotPathDelimiter)
+}
+
+func renderHistoryChange(change float64) string {
+	return fmt.Sprintf("%+.0f%% vs median", change*100)
+}
@@ -0,0 +1,85 @@
+package terminal
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_historyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	run := func(took time.Duration) []*SpanSnapshot {
+		return []*SpanSnapshot{
+			{Title: "build", Finished: true, Duration: took},
+		}
+	}
+
+	history := newHistoryStore(path, 0.2)
+	require.NoError(t, history.load())
+
+	_, exist := history.compare([]string{"build"}, time.Second)
+	assert.False(t, exist)
+
+	history.record(run(time.Second))
+	history.record(run(time.Second * 3))
+	history.record(run(time.Second * 2))
+	require.NoError(t, history.save())
+
+	loaded := newHistoryStore(path, 0.2)
+	require.NoError(t, loaded.load())
+
+	median, exist := loaded.median([]string{"build"})
+	require.True(t, exist)
+	assert.Equal(t, time.Second*2, median)
+
+	change, exist := loaded.compare([]string{"build"}, time.Millisecond*2700)
+	require.True(t, exist)
+	assert.InDelta(t, 0.35, change, 0.001)
+	assert.True(t, loaded.isRegression(time.Millisecond*2700, change))
+}
+
+func Test_spanEstimate(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	history := newHistoryStore("", 0.2)
+	history.record([]*SpanSnapshot{{Title: "build", Finished: true, Duration: time.Second * 10}})
+	history.record([]*SpanSnapshot{{Title: "build", Finished: true, Duration: time.Second * 30}})
+	history.record([]*SpanSnapshot{{Title: "build", Finished: true, Duration: time.Second * 20}})
+
+	tests := []struct {
+		name          string
+		title         string
+		elapsed       time.Duration
+		progress      int
+		finished      bool
+		wantExist     bool
+		wantEstimated int
+		wantRemaining time.Duration
+	}{
+		{name: "median of runs", title: "build", elapsed: time.Second * 5, wantExist: true, wantEstimated: 25, wantRemaining: time.Second * 15},
+		{name: "overdue", title: "build", elapsed: time.Second * 25, wantExist: true, wantEstimated: historyMaxEstimatedProgress, wantRemaining: -time.Second * 5},
+		{name: "without history", title: "deploy", elapsed: time.Second * 5},
+		{name: "explicit progress", title: "build", elapsed: time.Second * 5, progress: 10},
+		{name: "finished", title: "build", elapsed: time.Second * 5, finished: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := &Span{
+				title:    tt.title,
+				progress: tt.progress,
+				finished: tt.finished,
+				startAt:  now.Add(-tt.elapsed),
+				clock:    fixedClock(now),
+			}
+
+			estimated, remaining, exist := spanEstimate(span, &renderOpts{history: history})
+
+			assert.Equal(t, tt.wantExist, exist)
+			assert.Equal(t, tt.wantEstimated, estimated)
+			assert.Equal(t, tt.wantRemaining, remaining)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Package vt is minimal virtual terminal, that interpret
+// ANSI escape sequences emitted by terminal renderer into grid of chars
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	charEsc = 0x1b
+	charBel = 0x07
+
+	// second cell of wide (2 columns) char
+	cellContinuation = rune(0)
+)
+
+// Screen is virtual terminal screen with fixed size
+// supported: printable chars (with wide chars), \n, \r, \b,
+// cursor movement (CUP, CUU, CUD, CUF, CUB), erase display (ED),
+// erase line (EL). Colors (SGR) and unknown sequences are ignored
+type Screen struct {
+	width  int
+	height int
+	cells  [][]rune
+	x, y   int
+
+	pending []byte // incomplete escape sequence or utf8 char from previous write
+
+	mux sync.Mutex
+}
+
+func NewScreen(width, height int) *Screen {
+	s := &Screen{
+		width:  width,
+		height: height,
+	}
+
+	s.cells = make([][]rune, height)
+	for y := range s.cells {
+		s.cells[y] = s.emptyLine()
+	}
+
+	return s
+}
+
+// Size return screen size in chars
+func (s *Screen) Size() (width, height int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.width, s.height
+}
+
+// Resize will change screen size, content is kept (and cut)
+func (s *Screen) Resize(width, height int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	cells := make([][]rune, height)
+	for y := range cells {
+		cells[y] = make([]rune, width)
+		for x := range cells[y] {
+			cells[y][x] = ' '
+
+			if y < s.height && x < s.width {
+				cells[y][x] = s.cells[y][x]
+			}
+		}
+	}
+
+	s.width, s.height, s.cells = width, height, cells
+	s.x, s.y = clamp(s.x, 0, width-1), clamp(s.y, 0, height-1)
+}
+
+// String return all screen lines, without trailing spaces
+// and trailing empty lines
+func (s *Screen) String() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	lines := make([]string, 0, s.height)
+	for y := range s.cells {
+		lines = append(lines, s.line(y))
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// Line return screen line without trailing spaces
+func (s *Screen) Line(y int) string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if y < 0 || y >= s.height {
+		return ""
+	}
+
+	return s.line(y)
+}
+
+// Write interpret p as terminal output
+func (s *Screen) Write(p []byte) (int, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	data := append(s.pending, p...)
+	s.pending = nil
+
+	for len(data) > 0 {
+		if data[0] == charEsc {
+			size, complete := s.escape(data)
+			if !complete {
+				s.pending = append([]byte(nil), data...)
+				break
+			}
+
+			data = data[size:]
+			continue
+		}
+
+		if !utf8.FullRune(data) {
+			s.pending = append([]byte(nil), data...)
+			break
+		}
+
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+
+		s.char(r)
+	}
+
+	return len(p), nil
+}
+
+func (s *Screen) char(r rune) {
+	switch r {
+	case '\n':
+		// terminal in normal (cooked) mode translate LF to CR+LF
+		s.x = 0
+		s.lineFeed()
+		return
+	case '\r':
+		s.x = 0
+		return
+	case '\b':
+		if s.x > 0 {
+			s.x--
+		}
+		return
+	case '\t':
+		s.x = clamp((s.x/8+1)*8, 0, s.width-1)
+		return
+	}
+
+	if r < 0x20 || r == 0x7f {
+		// other control chars is not printable
+		return
+	}
+
+	width := runewidth.RuneWidth(r)
+	if width == 0 || width > s.width {
+		// wide char is clipped, when it can't fit even in empty line
+		return
+	}
+
+	// auto wrap
+	if s.x+width > s.width {
+		s.x = 0
+		s.lineFeed()
+	}
+
+	s.cells[s.y][s.x] = r
+	if width == 2 {
+		s.cells[s.y][s.x+1] = cellContinuation
+	}
+
+	s.x += width
+}
+
+func (s *Screen) lineFeed() {
+	if s.y < s.height-1 {
+		s.y++
+		return
+	}
+
+	// scroll
+	s.cells = append(s.cells[1:], s.emptyLine())
+}
+
+// escape will handle escape sequence, and return it size in bytes
+// complete=false when sequence is not fully written yet
+func (s *Screen) escape(data []byte) (size int, complete bool) {
+	if len(data) < 2 {
+		return 0, false
+	}
+
+	switch data[1] {
+	case '[':
+		// CSI: ESC [ params final
+		for i := 2; i < len(data); i++ {
+			if data[i] >= 0x40 && data[i] <= 0x7e {
+				s.csi(string(data[2:i]), data[i])
+				return i + 1, true
+			}
+		}
+
+		return 0, false
+	case ']':
+		// OSC: ESC ] ... (BEL | ESC \)
+		for i := 2; i < len(data); i++ {
+			if data[i] == charBel {
+				return i + 1, true
+			}
+
+			if data[i] == charEsc && i+1 < len(data) && data[i+1] == '\\' {
+				return i + 2, true
+			}
+		}
+
+		return 0, false
+	}
+
+	// other two byte sequences is ignored
+	return 2, true
+}
+
+func (s *Screen) csi(params string, final byte) {
+	args := strings.Split(params, ";")
+	arg := func(idx, def int) int {
+		if idx >= len(args) || args[idx] == "" {
+			return def
+		}
+
+		value, err := strconv.Atoi(strings.TrimLeft(args[idx], "?"))
+		if err != nil {
+			return def
+		}
+
+		return value
+	}
+
+	switch final {
+	case 'H', 'f':
+		s.y = clamp(arg(0, 1)-1, 0, s.height-1)
+		s.x = clamp(arg(1, 1)-1, 0, s.width-1)
+	case 'A':
+		s.y = clamp(s.y-arg(0, 1), 0, s.height-1)
+	case 'B':
+		s.y = clamp(s.y+arg(0, 1), 0, s.height-1)
+	case 'C':
+		s.x = clamp(s.x+arg(0, 1), 0, s.width-1)
+	case 'D':
+		s.x = clamp(s.x-arg(0, 1), 0, s.width-1)
+	case 'G':
+		s.x = clamp(arg(0, 1)-1, 0, s.width-1)
+	case 'J':
+		s.eraseDisplay(arg(0, 0))
+	case 'K':
+		s.eraseLine(arg(0, 0))
+	}
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for y := s.y + 1; y < s.height; y++ {
+			s.cells[y] = s.emptyLine()
+		}
+	case 1:
+		s.eraseLine(1)
+		for y := 0; y < s.y; y++ {
+			s.cells[y] = s.emptyLine()
+		}
+	default:
+		for y := range s.cells {
+			s.cells[y] = s.emptyLine()
+		}
+	}
+}
+
+func (s *Screen) eraseLine(mode int) {
+	from, to := 0, s.width
+
+	switch mode {
+	case 0:
+		from = s.x
+	case 1:
+		to = s.x + 1
+	}
+
+	for x := from; x < to && x < s.width; x++ {
+		s.cells[s.y][x] = ' '
+	}
+}
+
+func (s *Screen) line(y int) string {
+	line := strings.Builder{}
+
+	for _, r := range s.cells[y] {
+		if r == cellContinuation {
+			continue
+		}
+
+		line.WriteRune(r)
+	}
+
+	return strings.TrimRight(line.String(), " ")
+}
+
+func (s *Screen) emptyLine() []rune {
+	line := make([]rune, s.width)
+	for x := range line {
+		line[x] = ' '
+	}
+
+	return line
+}
+
+func clamp(value, from, to int) int {
+	if value > to {
+		value = to
+	}
+
+	if value < from {
+		value = from
+	}
+
+	return value
+}
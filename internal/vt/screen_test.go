@@ -0,0 +1,91 @@
+package vt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScreen(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		input  []string
+		want   string
+	}{
+		{
+			name:   "text and new lines",
+			width:  10,
+			height: 3,
+			input:  []string{"hello\nworld"},
+			want:   "hello\nworld",
+		},
+		{
+			name:   "clear and move cursor",
+			width:  10,
+			height: 3,
+			input:  []string{"garbage\n", "\033[2J\033[2;3Hok"},
+			want:   "\n  ok",
+		},
+		{
+			name:   "colors ignored, sequence split between writes",
+			width:  10,
+			height: 2,
+			input:  []string{"\033[1;3", "3mred\033[0m"},
+			want:   "red",
+		},
+		{
+			name:   "wrap and scroll",
+			width:  4,
+			height: 2,
+			input:  []string{"abcdefgh\nij"},
+			want:   "efgh\nij",
+		},
+		{
+			name:   "wide chars",
+			width:  5,
+			height: 2,
+			input:  []string{"日本語"},
+			want:   "日本\n語",
+		},
+		{
+			name:   "wide char in last column is wrapped",
+			width:  3,
+			height: 2,
+			input:  []string{"ab日c"},
+			want:   "ab\n日c",
+		},
+		{
+			name:   "wide char at cursor in last column",
+			width:  3,
+			height: 2,
+			input:  []string{"\033[1;3H日"},
+			want:   "\n日",
+		},
+		{
+			name:   "wide char wider than screen is clipped",
+			width:  1,
+			height: 2,
+			input:  []string{"日a"},
+			want:   "a",
+		},
+		{
+			name:   "erase line",
+			width:  10,
+			height: 1,
+			input:  []string{"abcdef\r\033[3C\033[K"},
+			want:   "abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := NewScreen(tt.width, tt.height)
+			for _, input := range tt.input {
+				_, _ = screen.Write([]byte(input))
+			}
+
+			assert.Equal(t, tt.want, screen.String())
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package terminal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// title delimiter for nested test cases (level 3 and deeper)
+const junitPathDelimiter = " / "
+
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Skipped  int              `xml:"skipped,attr"`
+		Time     string           `xml:"time,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name      string          `xml:"name,attr"`
+		ID        int             `xml:"id,attr"`
+		Tests     int             `xml:"tests,attr"`
+		Failures  int             `xml:"failures,attr"`
+		Skipped   int             `xml:"skipped,attr"`
+		Time      string          `xml:"time,attr"`
+		Timestamp string          `xml:"timestamp,attr"`
+		Cases     []junitTestCase `xml:"testcase"`
+		SystemOut string          `xml:"system-out,omitempty"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Time      string        `xml:"time,attr"`
+		Failure   *junitMessage `xml:"failure,omitempty"`
+		Skipped   *junitMessage `xml:"skipped,omitempty"`
+		SystemOut string        `xml:"system-out,omitempty"`
+	}
+
+	junitMessage struct {
+		Message string `xml:"message,attr"`
+		Content string `xml:",chardata"`
+	}
+)
+
+// WriteJUnit will write all spans as JUnit XML report:
+//   - root spans      = test suites
+//   - all child spans = test cases (nested titles joined with " / ")
+//
+// failed spans (see Span.SetError) reported as failures,
+// not finished spans reported as skipped,
+// span logs written as system-out
+func (t *Terminal) WriteJUnit(w io.Writer) error {
+	report := junitTestSuites{}
+	total := time.Duration(0)
+
+	for idx, root := range t.Snapshot() {
+		suite := junitSuiteFromSpan(idx, root)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+		total += root.Duration
+	}
+
+	report.Time = junitDuration(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSuiteFromSpan(id int, root *SpanSnapshot) junitTestSuite {
+	suite := junitTestSuite{
+		Name:      root.Title,
+		ID:        id,
+		Time:      junitDuration(root.Duration),
+		Timestamp: root.StartAt.Format("2006-01-02T15:04:05"),
+		SystemOut: strings.Join(root.Logs, "\n"),
+	}
+
+	// root without child, or failed by itself
+	// is also test case, otherwise failure will be lost
+	if len(root.Children) == 0 || root.Error != "" {
+		suite.Cases = append(suite.Cases, junitCaseFromSpan(root.Title, root.Title, root))
+	}
+
+	var walk func(prefix string, spans []*SpanSnapshot)
+	walk = func(prefix string, spans []*SpanSnapshot) {
+		for _, span := range spans {
+			name := prefix + span.Title
+
+			suite.Cases = append(suite.Cases, junitCaseFromSpan(root.Title, name, span))
+			walk(name+junitPathDelimiter, span.Children)
+		}
+	}
+	walk("", root.Children)
+
+	for _, testCase := range suite.Cases {
+		suite.Tests++
+
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+
+		if testCase.Skipped != nil {
+			suite.Skipped++
+		}
+	}
+
+	return suite
+}
+
+func junitCaseFromSpan(className string, name string, span *SpanSnapshot) junitTestCase {
+	testCase := junitTestCase{
+		Name:      name,
+		ClassName: className,
+		Time:      junitDuration(span.Duration),
+		SystemOut: strings.Join(span.Logs, "\n"),
+	}
+
+	if span.Error != "" {
+		testCase.Failure = &junitMessage{
+			Message: span.Error,
+			Content: span.Error,
+		}
+
+		return testCase
+	}
+
+	if !span.Finished {
+		testCase.Skipped = &junitMessage{
+			Message: "span is not finished",
+		}
+	}
+
+	return testCase
+}
+
+func junitDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,48 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal_WriteJUnit(t *testing.T) {
+	term := NewTerminal()
+	term.setActive(true)
+	term.setRecording(true)
+
+	ctx, root := term.span(context.Background(), WithTitle("build"))
+	ctx, compile := term.span(ctx, WithTitle("compile"))
+	_, link := term.span(ctx, WithTitle("link"))
+	link.Write("undefined: main")
+	link.SetError(errors.New("link failed"))
+	compile.End()
+	root.End()
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, term.WriteJUnit(buf))
+
+	report := junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, 2, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	require.Len(t, report.Suites, 1)
+
+	suite := report.Suites[0]
+	assert.Equal(t, "build", suite.Name)
+	assert.Empty(t, suite.SystemOut, "child output is not repeated in parent")
+	require.Len(t, suite.Cases, 2)
+	assert.Equal(t, "compile", suite.Cases[0].Name)
+	assert.Nil(t, suite.Cases[0].Failure)
+	assert.Equal(t, "compile / link", suite.Cases[1].Name)
+	assert.Equal(t, "build", suite.Cases[1].ClassName)
+	assert.Equal(t, "undefined: main", suite.Cases[1].SystemOut)
+	require.NotNil(t, suite.Cases[1].Failure)
+	assert.Equal(t, "link failed", suite.Cases[1].Failure.Message)
+}
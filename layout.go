@@ -0,0 +1,110 @@
+package terminal
+
+import (
+	"fmt"
+	"sort"
+)
+
+// layout priority of line, when frame not fit into terminal height
+// lines with lower priority are removed first
+const (
+	priorityBlank             layoutPriority = iota // empty separator lines
+	priorityLogs                                    // span logs, except latest line
+	priorityStdout                                  // captured stdout, except latest line
+	priorityFinishedDetails                         // finished spans on details level (3)
+	priorityFinishedOperation                       // finished spans on operation level (2)
+	priorityDetails                                 // running spans on details level (3)
+	priorityLatestLog                               // latest line of span logs and captured stdout
+	priorityOperation                               // running spans on operation level (2)
+	priorityFinishedRoot                            // header of finished root span
+	priorityRoot                                    // header of running root span
+	priorityPinned                                  // pinned spans, removed after all other lines
+)
+
+type (
+	layoutPriority int
+
+	layoutLine struct {
+		text     string
+		priority layoutPriority
+	}
+
+	// layout is frame lines, that can be fit into terminal height
+	layout []layoutLine
+)
+
+func (l *layout) add(priority layoutPriority, text string) {
+	*l = append(*l, layoutLine{text: text, priority: priority})
+}
+
+func (l *layout) blank() {
+	l.add(priorityBlank, "")
+}
+
+// addChild will add all child lines, first line is prefixed
+func (l *layout) addChild(prefix string, child layout) {
+	if len(child) == 0 {
+		return
+	}
+
+	child[0].text = prefix + child[0].text
+	*l = append(*l, child...)
+}
+
+// addContainer will add all container lines, latest line
+// is more important than others
+func (l *layout) addContainer(c container, opt *renderOpts, priority layoutPriority) {
+	lines := c.content()
+
+	if dropped := c.dropped(); dropped > 0 {
+		l.add(priority, styleHistory.Render(opt.logsPrefix+renderDroppedLines(dropped)))
+	}
+
+	for ind, line := range lines {
+		linePriority := priority
+		if ind == len(lines)-1 {
+			linePriority = priorityLatestLog
+		}
+
+		l.add(linePriority, styleLogs.Render(opt.logsPrefix+truncateMiddle(line, opt.logsMaxLength)))
+	}
+}
+
+// fit will remove less important lines, until layout fit into height
+// lines with same priority removed from top to bottom (older first)
+// all lines are kept, when height is unknown (zero)
+func (l layout) fit(height int) []string {
+	removed := make([]bool, len(l))
+
+	if height > 0 && len(l) > height {
+		order := make([]int, len(l))
+		for ind := range order {
+			order[ind] = ind
+		}
+
+		sort.SliceStable(order, func(i, j int) bool {
+			return l[order[i]].priority < l[order[j]].priority
+		})
+
+		for _, ind := range order[:len(l)-height] {
+			removed[ind] = true
+		}
+	}
+
+	lines := make([]string, 0, len(l))
+	for ind, line := range l {
+		if !removed[ind] {
+			lines = append(lines, line.text)
+		}
+	}
+
+	return lines
+}
+
+func renderDroppedLines(count int) string {
+	if count == 1 {
+		return "… 1 earlier line"
+	}
+
+	return fmt.Sprintf("… %d earlier lines", count)
+}
@@ -0,0 +1,122 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type markdownRow struct {
+	path []string
+	span *SpanSnapshot
+}
+
+// WriteMarkdownSummary will write table with all spans
+// (path, status, duration, attributes), slowest spans first
+// output is compatible with GitHub step summaries
+func (t *Terminal) WriteMarkdownSummary(w io.Writer) error {
+	rows := make([]markdownRow, 0)
+	spans := visibleSnapshots(t.Snapshot())
+
+	walkSnapshots(spans, func(path []string, span *SpanSnapshot) {
+		rows = append(rows, markdownRow{path: path, span: span})
+	})
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].span.Duration > rows[j].span.Duration
+	})
+
+	failed := 0
+	for _, row := range rows {
+		if row.span.Error != "" {
+			failed++
+		}
+	}
+
+	out := strings.Builder{}
+	out.WriteString("### Run summary\n\n")
+	out.WriteString(fmt.Sprintf("%d spans, %d failed\n\n", len(rows), failed))
+	out.WriteString("| Span | Status | Duration | Attributes |\n")
+	out.WriteString("|------|--------|---------:|------------|\n")
+
+	for _, row := range rows {
+		out.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			markdownEscape(strings.Join(row.path, snapshotPathDelimiter)),
+			markdownStatus(row.span),
+			renderSnapshotDuration(row.span),
+			markdownEscape(markdownAttributes(row.span.Attributes)),
+		))
+	}
+
+	out.WriteString("\n")
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func (t *Terminal) writeReleaseMarkdownSummary() {
+	for _, w := range t.opts.markdownSummaryWriters {
+		if err := t.WriteMarkdownSummary(w); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed write markdown summary: %v\n", err)
+		}
+	}
+
+	for _, path := range t.opts.markdownSummaryFiles {
+		if err := t.writeMarkdownSummaryFile(path); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "failed write markdown summary: %v\n", err)
+		}
+	}
+}
+
+func (t *Terminal) writeMarkdownSummaryFile(path string) error {
+	// step summary files is shared between all steps,
+	// so we always append to it
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if err := t.WriteMarkdownSummary(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func markdownStatus(span *SpanSnapshot) string {
+	if span.Error != "" {
+		return "❌ " + markdownEscape(span.Error)
+	}
+
+	if !span.Finished {
+		return "⏳ running"
+	}
+
+	return "✅ ok"
+}
+
+func markdownAttributes(attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("`%s=%s`", key, attributes[key]))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", " ")
+
+	return s
+}
@@ -0,0 +1,86 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal_WriteMarkdownSummary(t *testing.T) {
+	startAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	term := NewTerminal(WithHeadless())
+
+	ctx, root := term.StartSpan(context.Background(), "build", WithAttribute("os", "linux"))
+	_, test := term.StartSpan(ctx, "test | unit")
+	test.SetError(errors.New("2 tests\nfailed"))
+	test.End()
+	root.End()
+	_, _ = term.StartSpan(context.Background(), "deploy")
+
+	term.tree.read(func(roots []*Span) {
+		for _, span := range append(roots, test) {
+			span.startAt = startAt
+		}
+	})
+	root.endAt = startAt.Add(time.Second * 3)
+	test.endAt = startAt.Add(time.Millisecond * 1500)
+
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, term.WriteMarkdownSummary(buf))
+
+	lines := strings.Split(buf.String(), "\n")
+	require.GreaterOrEqual(t, len(lines), 9)
+
+	assert.Equal(t, "### Run summary", lines[0])
+	assert.Equal(t, "3 spans, 1 failed", lines[2])
+	assert.Equal(t, "| Span | Status | Duration | Attributes |", lines[4])
+	assert.Equal(t, "| build | ✅ ok | 3s | `os=linux` |", lines[7])
+	assert.Equal(t, "| build › test \\| unit | ❌ 2 tests failed | 2s |  |", lines[8])
+
+	// running span took longest, because it is still running
+	assert.True(t, strings.HasPrefix(lines[6], "| deploy | ⏳ running |"))
+}
+
+func TestTerminal_Release_markdownSummary(t *testing.T) {
+	t.Run("not terminal output", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "summary.md")
+
+		term := NewTerminal(WithMarkdownSummaryFile(path))
+		term.isANSITerminal = false // like in CI
+
+		term.Capture()
+		_, span := term.StartSpan(context.Background(), "build")
+		require.NotNil(t, span, "spans are recorded without terminal output")
+		span.End()
+
+		term.Release()
+		term.Release() // summary is written only once
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(string(content), "### Run summary"))
+		assert.Contains(t, string(content), "| build | ✅ ok |")
+	})
+
+	t.Run("headless", func(t *testing.T) {
+		buf := bytes.NewBuffer(nil)
+		term := NewTerminal(WithHeadless(), WithMarkdownSummary(buf))
+
+		term.Release()
+		assert.Empty(t, buf.String(), "nothing recorded")
+
+		_, span := term.StartSpan(context.Background(), "build")
+		span.End()
+
+		term.Release()
+		assert.Contains(t, buf.String(), "| build | ✅ ok |")
+	})
+}
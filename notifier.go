@@ -0,0 +1,30 @@
+package terminal
+
+// changeNotifier signal watcher that something is changed
+// many signals between reads are merged into one, so
+// notify never block and is cheap enough to call on every change
+type changeNotifier struct {
+	c chan struct{}
+}
+
+func newChangeNotifier() *changeNotifier {
+	return &changeNotifier{
+		c: make(chan struct{}, 1),
+	}
+}
+
+func (n *changeNotifier) notify() {
+	if n == nil {
+		return
+	}
+
+	select {
+	case n.c <- struct{}{}:
+	default:
+		// already notified, watcher not read it yet
+	}
+}
+
+func (n *changeNotifier) changed() <-chan struct{} {
+	return n.c
+}
@@ -0,0 +1,76 @@
+package terminal
+
+import "time"
+
+const (
+	SpanEventStart    SpanEventKind = iota // span is started
+	SpanEventWrite                         // log is written to span
+	SpanEventProgress                      // span progress is updated
+	SpanEventError                         // span error is set (or reset)
+	SpanEventEnd                           // span is ended
+)
+
+type (
+	SpanEventKind int
+
+	// SpanEvent describe single change of span
+	SpanEvent struct {
+		Kind     SpanEventKind
+		SpanID   int64
+		ParentID int64 // 0 for root spans
+		Title    string
+		Depth    int
+		Message  string // SpanEventWrite only
+		Progress int    // current progress in %, 0 .. 100
+		Err      error  // SpanEventError only
+		At       time.Time
+	}
+
+	// SpanObserver will receive all span events in order of happening
+	// for each span. Observer is called while span is locked, so
+	// it should be fast and never call span methods
+	SpanObserver = func(SpanEvent)
+)
+
+func (k SpanEventKind) String() string {
+	switch k {
+	case SpanEventStart:
+		return "start"
+	case SpanEventWrite:
+		return "write"
+	case SpanEventProgress:
+		return "progress"
+	case SpanEventError:
+		return "error"
+	case SpanEventEnd:
+		return "end"
+	}
+
+	return "unknown"
+}
+
+func (s *Span) emit(kind SpanEventKind, message string) {
+	if s.observer == nil {
+		return
+	}
+
+	event := SpanEvent{
+		Kind:     kind,
+		SpanID:   int64(s.id),
+		Title:    s.title,
+		Depth:    int(s.depth),
+		Message:  message,
+		Progress: s.progress,
+		At:       s.now(),
+	}
+
+	if s.parent != nil {
+		event.ParentID = int64(s.parent.id)
+	}
+
+	if kind == SpanEventError {
+		event.Err = s.err
+	}
+
+	s.observer(event)
+}
@@ -0,0 +1,214 @@
+package terminal
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// profile.proto field numbers
+// https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	pprofProfileSampleType    = 1
+	pprofProfileSample        = 2
+	pprofProfileLocation      = 4
+	pprofProfileFunction      = 5
+	pprofProfileStringTable   = 6
+	pprofProfileTimeNanos     = 9
+	pprofProfileDurationNanos = 10
+	pprofProfilePeriodType    = 11
+	pprofProfilePeriod        = 12
+	pprofProfileDefaultSample = 14
+
+	pprofValueTypeType = 1
+	pprofValueTypeUnit = 2
+
+	pprofSampleLocationID = 1
+	pprofSampleValue      = 2
+
+	pprofLocationID   = 1
+	pprofLocationLine = 4
+
+	pprofLineFunctionID = 1
+
+	pprofFunctionID         = 1
+	pprofFunctionName       = 2
+	pprofFunctionSystemName = 3
+)
+
+type pprofBuilder struct {
+	strings   []string
+	stringIdx map[string]int64
+	functions map[string]uint64 // span title -> function id (same as location id)
+	profile   protoBuffer
+}
+
+// WritePprof will write all spans as gzipped pprof profile (profile.proto)
+// each span stack is titles of all its ancestors, sample values:
+//   - wall  - span self time (without child spans), total time is "cum" in pprof
+//   - spans - count of spans with same stack
+//
+// profile can be explored with `go tool pprof -http=: profile.pb.gz`
+func (t *Terminal) WritePprof(w io.Writer) error {
+	spans := t.Snapshot()
+	b := &pprofBuilder{
+		stringIdx: make(map[string]int64),
+		functions: make(map[string]uint64),
+	}
+
+	b.str("") // string table should start from empty string
+
+	b.profile.message(pprofProfileSampleType, b.valueType("wall", "nanoseconds"))
+	b.profile.message(pprofProfileSampleType, b.valueType("spans", "count"))
+
+	walkSnapshots(spans, func(path []string, span *SpanSnapshot) {
+		// sample stack is from leaf to root
+		locations := make([]uint64, 0, len(path))
+		for i := len(path) - 1; i >= 0; i-- {
+			locations = append(locations, b.location(path[i]))
+		}
+
+		sample := protoBuffer{}
+		sample.packedUint64(pprofSampleLocationID, locations)
+		sample.packedInt64(pprofSampleValue, []int64{int64(selfDuration(span)), 1})
+		b.profile.message(pprofProfileSample, sample)
+	})
+
+	if runStart, runEnd := snapshotsTimeRange(spans); !runStart.IsZero() {
+		b.profile.int64(pprofProfileTimeNanos, runStart.UnixNano())
+		b.profile.int64(pprofProfileDurationNanos, int64(runEnd.Sub(runStart)))
+	}
+
+	b.profile.message(pprofProfilePeriodType, b.valueType("wall", "nanoseconds"))
+	b.profile.int64(pprofProfilePeriod, 1)
+	b.profile.int64(pprofProfileDefaultSample, b.str("wall"))
+
+	// string table must be last, all strings is collected
+	for _, s := range b.strings {
+		b.profile.string(pprofProfileStringTable, s)
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b.profile.bytes); err != nil {
+		_ = gz.Close()
+		return err
+	}
+
+	return gz.Close()
+}
+
+func (b *pprofBuilder) str(s string) int64 {
+	if idx, exist := b.stringIdx[s]; exist {
+		return idx
+	}
+
+	idx := int64(len(b.strings))
+	b.strings = append(b.strings, s)
+	b.stringIdx[s] = idx
+
+	return idx
+}
+
+func (b *pprofBuilder) valueType(typ, unit string) protoBuffer {
+	value := protoBuffer{}
+	value.int64(pprofValueTypeType, b.str(typ))
+	value.int64(pprofValueTypeUnit, b.str(unit))
+
+	return value
+}
+
+// location return location id for span title
+// every title is mapped to one function and one location
+func (b *pprofBuilder) location(title string) uint64 {
+	if id, exist := b.functions[title]; exist {
+		return id
+	}
+
+	id := uint64(len(b.functions) + 1)
+	b.functions[title] = id
+
+	function := protoBuffer{}
+	function.uint64(pprofFunctionID, id)
+	function.int64(pprofFunctionName, b.str(title))
+	function.int64(pprofFunctionSystemName, b.str(title))
+	b.profile.message(pprofProfileFunction, function)
+
+	line := protoBuffer{}
+	line.uint64(pprofLineFunctionID, id)
+
+	location := protoBuffer{}
+	location.uint64(pprofLocationID, id)
+	location.message(pprofLocationLine, line)
+	b.profile.message(pprofProfileLocation, location)
+
+	return id
+}
+
+// ------------------
+
+// protoBuffer is minimal protobuf wire format encoder
+// enough for writing profile.proto messages
+type protoBuffer struct {
+	bytes []byte
+}
+
+const (
+	protoWireVarint = 0
+	protoWireBytes  = 2
+)
+
+func (p *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		p.bytes = append(p.bytes, byte(x)|0x80)
+		x >>= 7
+	}
+
+	p.bytes = append(p.bytes, byte(x))
+}
+
+func (p *protoBuffer) key(field int, wire int) {
+	p.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (p *protoBuffer) uint64(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+
+	p.key(field, protoWireVarint)
+	p.varint(x)
+}
+
+func (p *protoBuffer) int64(field int, x int64) {
+	p.uint64(field, uint64(x))
+}
+
+func (p *protoBuffer) string(field int, s string) {
+	// strings in string table should be written even if empty
+	p.key(field, protoWireBytes)
+	p.varint(uint64(len(s)))
+	p.bytes = append(p.bytes, s...)
+}
+
+func (p *protoBuffer) message(field int, m protoBuffer) {
+	p.key(field, protoWireBytes)
+	p.varint(uint64(len(m.bytes)))
+	p.bytes = append(p.bytes, m.bytes...)
+}
+
+func (p *protoBuffer) packedUint64(field int, values []uint64) {
+	packed := protoBuffer{}
+	for _, x := range values {
+		packed.varint(x)
+	}
+
+	p.message(field, packed)
+}
+
+func (p *protoBuffer) packedInt64(field int, values []int64) {
+	packed := protoBuffer{}
+	for _, x := range values {
+		packed.varint(uint64(x))
+	}
+
+	p.message(field, packed)
+}
@@ -0,0 +1,217 @@
+package terminal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// decodedProfile is part of profile.proto, enough for checking samples
+	decodedProfile struct {
+		sampleTypes []string // "type/unit"
+		samples     []decodedSample
+		functions   map[uint64]string // id -> name
+		locations   map[uint64]uint64 // id -> function id
+		timeNanos   int64
+		duration    int64
+	}
+
+	decodedSample struct {
+		stack  string // root;..;leaf
+		values []int64
+	}
+
+	protoField struct {
+		field  int
+		varint uint64
+		bytes  []byte
+	}
+)
+
+func TestTerminal_WritePprof(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, newExportTestTerminal().WritePprof(buf))
+
+	profile := decodeTestProfile(t, buf)
+
+	assert.Equal(t, []string{"wall/nanoseconds", "spans/count"}, profile.sampleTypes)
+	assert.Equal(t, time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC).UnixNano(), profile.timeNanos)
+	assert.Equal(t, int64(time.Second*4), profile.duration)
+	assert.Len(t, profile.functions, 6, "one function per unique title")
+
+	assert.Equal(t, []decodedSample{
+		{stack: "build", values: []int64{int64(time.Millisecond * 500), 1}},
+		{stack: "build;compile", values: []int64{int64(time.Second), 1}},
+		{stack: "build;test", values: []int64{int64(time.Millisecond * 500), 1}},
+		{stack: "build;test;unit", values: []int64{int64(time.Second), 1}},
+		{stack: "deploy", values: []int64{int64(time.Millisecond * 500), 1}},
+		{stack: "deploy;upload; retry", values: []int64{int64(time.Millisecond * 500), 1}},
+	}, profile.samples)
+}
+
+func decodeTestProfile(t *testing.T, r io.Reader) decodedProfile {
+	t.Helper()
+
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	fields := decodeTestProto(t, data)
+
+	// string table is last in output, but
+	// all other fields are referencing it
+	strTable := make([]string, 0)
+	for _, f := range fields {
+		if f.field == pprofProfileStringTable {
+			strTable = append(strTable, string(f.bytes))
+		}
+	}
+
+	require.NotEmpty(t, strTable)
+	require.Equal(t, "", strTable[0], "string table should start from empty string")
+
+	profile := decodedProfile{
+		functions: make(map[uint64]string),
+		locations: make(map[uint64]uint64),
+	}
+
+	type rawSample struct {
+		locations []uint64
+		values    []int64
+	}
+	samples := make([]rawSample, 0)
+
+	for _, f := range fields {
+		switch f.field {
+		case pprofProfileSampleType:
+			typ, unit := "", ""
+			for _, v := range decodeTestProto(t, f.bytes) {
+				switch v.field {
+				case pprofValueTypeType:
+					typ = strTable[v.varint]
+				case pprofValueTypeUnit:
+					unit = strTable[v.varint]
+				}
+			}
+			profile.sampleTypes = append(profile.sampleTypes, typ+"/"+unit)
+		case pprofProfileSample:
+			sample := rawSample{}
+			for _, v := range decodeTestProto(t, f.bytes) {
+				for _, x := range decodeTestPacked(t, v.bytes) {
+					switch v.field {
+					case pprofSampleLocationID:
+						sample.locations = append(sample.locations, x)
+					case pprofSampleValue:
+						sample.values = append(sample.values, int64(x))
+					}
+				}
+			}
+			samples = append(samples, sample)
+		case pprofProfileFunction:
+			id, name := uint64(0), ""
+			for _, v := range decodeTestProto(t, f.bytes) {
+				switch v.field {
+				case pprofFunctionID:
+					id = v.varint
+				case pprofFunctionName:
+					name = strTable[v.varint]
+				}
+			}
+			profile.functions[id] = name
+		case pprofProfileLocation:
+			id, functionID := uint64(0), uint64(0)
+			for _, v := range decodeTestProto(t, f.bytes) {
+				switch v.field {
+				case pprofLocationID:
+					id = v.varint
+				case pprofLocationLine:
+					for _, line := range decodeTestProto(t, v.bytes) {
+						if line.field == pprofLineFunctionID {
+							functionID = line.varint
+						}
+					}
+				}
+			}
+			profile.locations[id] = functionID
+		case pprofProfileTimeNanos:
+			profile.timeNanos = int64(f.varint)
+		case pprofProfileDurationNanos:
+			profile.duration = int64(f.varint)
+		}
+	}
+
+	for _, sample := range samples {
+		// locations is from leaf to root
+		frames := make([]string, len(sample.locations))
+		for ind, locationID := range sample.locations {
+			functionID, exist := profile.locations[locationID]
+			require.True(t, exist, "unknown location %d", locationID)
+
+			frames[len(frames)-1-ind] = profile.functions[functionID]
+		}
+
+		profile.samples = append(profile.samples, decodedSample{
+			stack:  strings.Join(frames, ";"),
+			values: sample.values,
+		})
+	}
+
+	return profile
+}
+
+func decodeTestProto(t *testing.T, data []byte) []protoField {
+	t.Helper()
+
+	fields := make([]protoField, 0)
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		require.Greater(t, n, 0, "invalid key")
+		data = data[n:]
+
+		f := protoField{field: int(key >> 3)}
+
+		switch key & 7 {
+		case protoWireVarint:
+			f.varint, n = binary.Uvarint(data)
+			require.Greater(t, n, 0, "invalid varint")
+			data = data[n:]
+		case protoWireBytes:
+			size, n := binary.Uvarint(data)
+			require.Greater(t, n, 0, "invalid length")
+			require.LessOrEqual(t, uint64(n)+size, uint64(len(data)))
+			f.bytes = data[n : uint64(n)+size]
+			data = data[uint64(n)+size:]
+		default:
+			require.Failf(t, "unexpected wire type", "%d", key&7)
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func decodeTestPacked(t *testing.T, data []byte) []uint64 {
+	t.Helper()
+
+	values := make([]uint64, 0)
+	for len(data) > 0 {
+		x, n := binary.Uvarint(data)
+		require.Greater(t, n, 0, "invalid packed varint")
+
+		values = append(values, x)
+		data = data[n:]
+	}
+
+	return values
+}
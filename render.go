@@ -2,20 +2,19 @@ package terminal
 
 import (
 	"fmt"
-	"math"
 	"strings"
 	"time"
 )
 
-func renderSpanWithOptions(span *Span, opts renderOpts) string {
+func renderSpanWithOptions(span *Span, opts renderOpts) layout {
 	if span == nil {
-		return ""
+		return nil
 	}
 
 	return renderSpan(span, &opts)
 }
 
-func renderSpan(span *Span, opt *renderOpts) string {
+func renderSpan(span *Span, opt *renderOpts) layout {
 	switch span.depth {
 	case 0:
 		return renderSpanRoot(span, opt)
@@ -25,55 +24,113 @@ func renderSpan(span *Span, opt *renderOpts) string {
 		return renderSpanThird(span, opt)
 	}
 
-	return ""
+	return nil
 }
 
-func renderSpanRoot(span *Span, opt *renderOpts) string {
-	childContent := ""
-	for _, subSpan := range mostRelevantSpans(span.child, opt.spansMaxChild) {
-		childContent += "" +
-			renderSpanPadding(span) +
-			renderSpan(subSpan, opt)
+func renderSpanRoot(span *Span, opt *renderOpts) layout {
+	spanProgress := "-"
+	estimate := ""
+	if estimated, remaining, exist := spanEstimate(span, opt); exist {
+		spanProgress = fmt.Sprintf("~%2d%%", estimated)
+		estimate = " " + styleHistory.Render(renderEstimateRemaining(remaining))
+	}
+	if span.progress > 0 {
+		spanProgress = fmt.Sprintf("%2d%%", span.progress)
+	}
+	if span.finished {
+		spanProgress = "+"
+		estimate = renderSpanHistoryChange(span, opt)
+	}
+	if span.cancelling() {
+		estimate = " " + renderCancelling()
+	}
+
+	headerPriority := priorityRoot
+	if span.finished {
+		headerPriority = priorityFinishedRoot
 	}
+	headerPriority = spanPriority(span, headerPriority)
+
+	lines := layout{}
+	lines.add(headerPriority, styleHeader.Render("["+spanProgress+"] "+span.title)+estimate)
 
-	logs := ""
 	if !span.finished {
-		logs = renderContainer(span.container, opt) + "\n"
+		lines.addContainer(span.container, opt, priorityLogs)
+		lines.blank()
 	}
 
-	spanProgress := "-"
-	if span.progress > 0 {
-		spanProgress = fmt.Sprintf("%2d%%", span.progress)
+	children := mostRelevantSpans(span.child, opt.spansMaxChild, opt.relevance)
+	for _, subSpan := range children {
+		lines.addChild(renderSpanPadding(span), renderSpan(subSpan, opt))
 	}
+
+	if more := renderMoreSpans(children, len(span.child)-span.hiddenChild, span.finishedChild); more != "" {
+		lines.add(priorityFinishedOperation, renderSpanPadding(span)+more)
+	}
+
+	lines.blank()
+	return lines
+}
+
+func renderSpanSecond(span *Span, opt *renderOpts) layout {
+	lines := layout{}
+
 	if span.finished {
-		spanProgress = "+"
+		lines.add(spanPriority(span, priorityFinishedOperation), renderSpanStatusLine(span, opt))
+
+		// pinned details is displayed, even when operation is finished
+		for _, subSpan := range pinnedSpans(span.child) {
+			lines.addChild(renderSpanPadding(span), renderSpan(subSpan, opt))
+		}
+
+		return lines
 	}
 
-	return "" +
-		styleHeader.Render("["+spanProgress+"] "+span.title) + "\n" +
-		logs +
-		childContent + "\n"
+	lines.add(spanPriority(span, priorityOperation), renderSpanStatusLine(span, opt))
+
+	details := mostRelevantSpans(span.child, opt.spansMaxDetails, opt.relevance)
+	for _, subSpan := range details {
+		lines.addChild(renderSpanPadding(span), renderSpan(subSpan, opt))
+	}
+
+	if more := renderMoreSpans(details, len(span.child)-span.hiddenChild, span.finishedChild); more != "" {
+		lines.add(priorityFinishedDetails, renderSpanPadding(span)+more)
+	}
+
+	lines.blank()
+	return lines
 }
 
-func renderSpanSecond(span *Span, opt *renderOpts) string {
+func renderSpanThird(span *Span, opt *renderOpts) layout {
+	priority := priorityDetails
 	if span.finished {
-		return renderSpanStatusLine(span, opt) + "\n"
+		priority = priorityFinishedDetails
 	}
 
-	childContent := ""
-	for _, subSpan := range mostRelevantSpans(span.child, opt.spansMaxDetails) {
-		childContent += "" +
-			renderSpanPadding(span) +
-			renderSpan(subSpan, opt)
+	return layout{{text: renderSpanStatusLine(span, opt), priority: spanPriority(span, priority)}}
+}
+
+// spanPriority is layout priority of span status line,
+// pinned span lines are removed last
+func spanPriority(span *Span, priority layoutPriority) layoutPriority {
+	if span.pinned {
+		return priorityPinned
 	}
 
-	return "" +
-		renderSpanStatusLine(span, opt) + "\n" +
-		childContent + "\n"
+	return priority
 }
 
-func renderSpanThird(span *Span, opt *renderOpts) string {
-	return renderSpanStatusLine(span, opt) + "\n"
+// pinnedSpans return visible spans, that is pinned or has pinned descendants
+// should be called under tree read lock
+func pinnedSpans(spans []*Span) []*Span {
+	pinned := make([]*Span, 0)
+	for _, span := range spans {
+		if !span.hidden && span.isPinned() {
+			pinned = append(pinned, span)
+		}
+	}
+
+	return pinned
 }
 
 func renderSpanStatusLine(span *Span, opt *renderOpts) string {
@@ -88,20 +145,34 @@ func renderSpanStatusLine(span *Span, opt *renderOpts) string {
 		prefix = ">"
 	}
 
-	content := prefix + renderSpanProgress(span, opt) + delimiter + span.title
+	// estimate sort history samples, so it calculated once per line
+	estimated, remaining, hasEstimate := spanEstimate(span, opt)
+	content := prefix + renderSpanProgress(span, opt, estimated, hasEstimate) + delimiter + span.title
 
 	if span.finished {
-		return styleStatusDone.Render(content)
+		return styleStatusDone.Render(content) + renderSpanHistoryChange(span, opt)
+	}
+
+	if span.cancelling() {
+		return styleStatusActive.Render(content) + " " + renderCancelling()
+	}
+
+	if hasEstimate {
+		return styleStatusActive.Render(content) + " " + styleHistory.Render(renderEstimateRemaining(remaining))
 	}
 
 	return styleStatusActive.Render(content)
 }
 
-func renderSpanProgress(span *Span, opt *renderOpts) string {
+func renderSpanProgress(span *Span, opt *renderOpts, estimated int, hasEstimate bool) string {
 	if span.finished {
 		return fmt.Sprintf("%5s", renderDuration(span.startAt, span.endAt))
 	}
 
+	if hasEstimate {
+		return fmt.Sprintf(" ~%2d%%", estimated)
+	}
+
 	if span.progress == 0 {
 		return fmt.Sprintf("  %s", opt.progressZeroLabel)
 	}
@@ -109,9 +180,92 @@ func renderSpanProgress(span *Span, opt *renderOpts) string {
 	return fmt.Sprintf("  %2d%%", span.progress)
 }
 
+// renderMoreSpans is summary of spans, that not displayed
+// because of display limits, empty when all spans are shown
+func renderMoreSpans(shown []*Span, total, finished int) string {
+	if len(shown) >= total {
+		return ""
+	}
+
+	shownFinished := 0
+	for _, span := range shown {
+		if span.finished {
+			shownFinished++
+		}
+	}
+
+	hiddenFinished := finished - shownFinished
+	hiddenRunning := total - len(shown) - hiddenFinished
+
+	text := ""
+	switch {
+	case hiddenRunning > 0 && hiddenFinished > 0:
+		text = fmt.Sprintf("+%d more running, %d done", hiddenRunning, hiddenFinished)
+	case hiddenRunning > 0:
+		text = fmt.Sprintf("+%d more running", hiddenRunning)
+	default:
+		text = fmt.Sprintf("+%d more done", hiddenFinished)
+	}
+
+	return styleHistory.Render("  " + text)
+}
+
+func renderCancelling() string {
+	return styleCancelling.Render("cancelling…")
+}
+
+func renderSpanHistoryChange(span *Span, opt *renderOpts) string {
+	took := span.endAt.Sub(span.startAt)
+
+	change, exist := opt.history.compare(span.titlePath(), took)
+	if !exist {
+		return ""
+	}
+
+	if opt.history.isRegression(took, change) {
+		return " " + styleStatusFailed.Render(renderHistoryChange(change))
+	}
+
+	return " " + styleHistory.Render(renderHistoryChange(change))
+}
+
+// spanEstimate is estimated progress and remaining time, based
+// on durations of same span in previous runs
+// only for running spans without explicit progress
+func spanEstimate(span *Span, opt *renderOpts) (int, time.Duration, bool) {
+	if span.finished || span.progress > 0 {
+		return 0, 0, false
+	}
+
+	median, exist := opt.history.median(span.titlePath())
+	if !exist || median <= 0 {
+		return 0, 0, false
+	}
+
+	elapsed := span.now().Sub(span.startAt)
+	estimated := int(float64(elapsed) / float64(median) * 100)
+
+	// span is not finished yet, even when took longer than usual
+	if estimated > historyMaxEstimatedProgress {
+		estimated = historyMaxEstimatedProgress
+	}
+
+	return estimated, median - elapsed, true
+}
+
+func renderEstimateRemaining(remaining time.Duration) string {
+	if remaining <= 0 {
+		return "(est. overdue)"
+	}
+
+	return fmt.Sprintf("(est. ~%s left)", renderTook(remaining))
+}
+
 func renderDuration(from, to time.Time) string {
-	took := to.Sub(from)
+	return renderTook(to.Sub(from))
+}
 
+func renderTook(took time.Duration) string {
 	if took.Hours() > 1 {
 		return fmt.Sprintf("%.0fh", took.Hours())
 	}
@@ -127,30 +281,22 @@ func renderDuration(from, to time.Time) string {
 	return fmt.Sprintf("%dms", took.Milliseconds())
 }
 
-func renderSpanPadding(span *Span) string {
-	return strings.Repeat(" ", int(span.depth)) + " "
+func renderSnapshotDuration(span *SpanSnapshot) string {
+	return renderTook(span.Duration)
 }
 
-func renderContainer(c container, opt *renderOpts) string {
-	logs := ""
-
-	for _, line := range c.content() {
-		if len(line) > opt.logsMaxLength && opt.logsMaxLength > 0 {
-			half := int(math.Floor(float64(opt.logsMaxLength / 2)))
-			left := line[:half]
-			right := line[len(line)-half:]
-			line = left + " .. " + right
-		}
-
-		logs += opt.logsPrefix + line + "\n"
-	}
-
-	return styleLogs.Render(logs)
+func renderSpanPadding(span *Span) string {
+	return strings.Repeat(" ", int(span.depth)) + " "
 }
 
-func renderMainContainer(c container) string {
-	return renderContainer(c, &renderOpts{
+// renderMainContainer is captured stdout area
+func renderMainContainer(c container) layout {
+	lines := layout{}
+	lines.addContainer(c, &renderOpts{
 		logsMaxLength: 0,
 		logsPrefix:    "",
-	})
+	}, priorityStdout)
+	lines.blank()
+
+	return lines
 }
@@ -0,0 +1,332 @@
+package terminal
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fe3dback/span-terminal/internal/vt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden frames in testdata")
+
+func TestTerminal_update_golden(t *testing.T) {
+	startAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		opts   []OptsInitializer
+		fill   func(term *Terminal)
+	}{
+		{
+			name:   "empty",
+			width:  40,
+			height: 10,
+			fill:   func(_ *Terminal) {},
+		},
+		{
+			name:   "running_tree",
+			width:  60,
+			height: 20,
+			fill: func(term *Terminal) {
+				term.logsContainer.write("captured stdout line")
+
+				ctx, root := term.span(context.Background(), WithTitle("build"))
+				root.Write("root log")
+
+				ctx, compile := term.span(ctx, WithTitle("compile"))
+				compile.UpdateProgress(0.42)
+
+				_, parse := term.span(ctx, WithTitle("parse"))
+				parse.startAt = startAt
+				parse.End()
+				parse.endAt = startAt.Add(time.Millisecond * 120)
+
+				_, _ = term.span(ctx, WithTitle("link"))
+			},
+		},
+		{
+			name:   "finished_root",
+			width:  40,
+			height: 10,
+			fill: func(term *Terminal) {
+				ctx, root := term.span(context.Background(), WithTitle("deploy"))
+				_, upload := term.span(ctx, WithTitle("upload"))
+				upload.startAt = startAt
+				root.End()
+				upload.endAt = startAt.Add(time.Second * 3)
+			},
+		},
+		{
+			name:   "narrow",
+			width:  24,
+			height: 10,
+			fill: func(term *Terminal) {
+				term.logsContainer.write("captured stdout line, that is longer than terminal")
+
+				ctx, root := term.span(context.Background(), WithTitle("ビルドとデプロイのパイプライン"))
+				root.Write("🚀 deploying to production cluster")
+
+				_, _ = term.span(ctx, WithTitle("compile very long package name"))
+			},
+		},
+		{
+			name:   "overflow",
+			width:  40,
+			height: 12,
+			fill: func(term *Terminal) {
+				for i := 0; i < OptDefaultStdoutMaxLines; i++ {
+					term.logsContainer.write(fmt.Sprintf("stdout %d", i))
+				}
+
+				for _, title := range []string{"lint", "build"} {
+					ctx, root := term.span(context.Background(), WithTitle(title))
+					root.Write(title + " log 1")
+					root.Write(title + " log 2")
+
+					_, done := term.span(ctx, WithTitle(title+" prepare"))
+					done.startAt = startAt
+					done.End()
+					done.endAt = startAt.Add(time.Second * 2)
+
+					_, _ = term.span(ctx, WithTitle(title+" run"))
+				}
+			},
+		},
+		{
+			name:   "hidden",
+			width:  40,
+			height: 20,
+			opts: []OptsInitializer{
+				WithContainerMaxLines(2),
+				WithRenderOpts(
+					WithRenderOptSpanMaxRoots(1),
+					WithRenderOptSpanMaxChild(2),
+				),
+			},
+			fill: func(term *Terminal) {
+				_, done := term.span(context.Background(), WithTitle("prepare"))
+				done.End()
+
+				ctx, root := term.span(context.Background(), WithTitle("build"))
+				for i := 0; i < 5; i++ {
+					root.Write(fmt.Sprintf("log %d", i))
+				}
+
+				for i := 0; i < 6; i++ {
+					_, child := term.span(ctx, WithTitle(fmt.Sprintf("package %d", i)))
+					child.startAt = startAt
+					if i < 3 {
+						child.End()
+						child.endAt = startAt.Add(time.Second)
+					}
+				}
+			},
+		},
+		{
+			name:   "display_control",
+			width:  40,
+			height: 20,
+			opts: []OptsInitializer{
+				WithRenderOpts(WithRenderOptSpanMaxChild(2)),
+			},
+			fill: func(term *Terminal) {
+				ctx, root := term.span(context.Background(), WithTitle("build"))
+
+				_, pinned := term.span(ctx, WithTitle("migrations"), WithPinned())
+				pinned.startAt = startAt
+				pinned.End()
+				pinned.endAt = startAt.Add(time.Second)
+
+				_, _ = term.span(ctx, WithTitle("important"), WithPriority(10))
+
+				_, hidden := term.span(ctx, WithTitle("telemetry"), WithHidden())
+				hidden.Write("hidden log")
+
+				for i := 0; i < 3; i++ {
+					_, _ = term.span(ctx, WithTitle(fmt.Sprintf("package %d", i)))
+				}
+
+				root.Write("visible log")
+			},
+		},
+		{
+			name:   "estimate",
+			width:  50,
+			height: 10,
+			opts:   []OptsInitializer{WithClock(fixedClock(startAt))},
+			fill: func(term *Terminal) {
+				history := newHistoryStore("", 0.2)
+				history.record([]*SpanSnapshot{{
+					Title:    "build",
+					Finished: true,
+					Duration: time.Minute,
+					Children: []*SpanSnapshot{
+						{Title: "compile", Finished: true, Duration: time.Second * 40},
+						{Title: "test", Finished: true, Duration: time.Second * 10},
+					},
+				}})
+				term.opts.renderOpts.history = history
+
+				ctx, root := term.span(context.Background(), WithTitle("build"))
+				root.startAt = startAt.Add(-time.Second * 15)
+
+				_, compile := term.span(ctx, WithTitle("compile"))
+				compile.startAt = startAt.Add(-time.Second * 10)
+
+				_, test := term.span(ctx, WithTitle("test"))
+				test.startAt = startAt.Add(-time.Second * 12)
+			},
+		},
+		{
+			name:   "history_change",
+			width:  50,
+			height: 10,
+			fill: func(term *Terminal) {
+				history := newHistoryStore("", 0.2)
+				history.record([]*SpanSnapshot{
+					{Title: "lint", Finished: true, Duration: time.Second * 10},
+					{Title: "build", Finished: true, Duration: time.Second * 10, Children: []*SpanSnapshot{
+						{Title: "compile", Finished: true, Duration: time.Second * 4},
+					}},
+				})
+				term.opts.renderOpts.history = history
+
+				_, lint := term.span(context.Background(), WithTitle("lint"))
+				lint.startAt = startAt
+				lint.End()
+				lint.endAt = startAt.Add(time.Second * 9)
+
+				ctx, build := term.span(context.Background(), WithTitle("build"))
+				_, compile := term.span(ctx, WithTitle("compile"))
+				build.startAt, compile.startAt = startAt, startAt
+				build.End()
+				build.endAt = startAt.Add(time.Second * 13)
+				compile.endAt = startAt.Add(time.Second * 5)
+			},
+		},
+		{
+			name:   "pinned_detail",
+			width:  40,
+			height: 20,
+			opts: []OptsInitializer{
+				WithRenderOpts(WithRenderOptSpanMaxChild(1)),
+			},
+			fill: func(term *Terminal) {
+				ctx, _ := term.span(context.Background(), WithTitle("build"))
+
+				opCtx, _ := term.span(ctx, WithTitle("op A"))
+				_, _ = term.span(opCtx, WithTitle("CRITICAL"), WithPinned())
+				_, _ = term.span(opCtx, WithTitle("regular"))
+
+				_, _ = term.span(ctx, WithTitle("op B"))
+
+				// pinned detail of finished operation
+				opCtx, opC := term.span(ctx, WithTitle("op C"))
+				_, migrations := term.span(opCtx, WithTitle("migrations"), WithPinned())
+				_, _ = term.span(opCtx, WithTitle("seed"))
+				opC.startAt, migrations.startAt = startAt, startAt
+				opC.End()
+				opC.endAt, migrations.endAt = startAt.Add(time.Second*2), startAt.Add(time.Second)
+			},
+		},
+		{
+			name:   "pinned_small_height",
+			width:  40,
+			height: 4,
+			opts: []OptsInitializer{
+				WithRenderOpts(WithRenderOptSpanMaxChild(1)),
+			},
+			fill: func(term *Terminal) {
+				ctx, root := term.span(context.Background(), WithTitle("build"))
+				root.Write("root log")
+
+				opCtx, _ := term.span(ctx, WithTitle("op A"))
+				_, _ = term.span(opCtx, WithTitle("CRITICAL"), WithPinned())
+				_, _ = term.span(ctx, WithTitle("op B"))
+			},
+		},
+		{
+			name:   "cancelling",
+			width:  40,
+			height: 10,
+			fill: func(term *Terminal) {
+				ctx, root := term.span(term.Context(), WithTitle("build"))
+				_, _ = term.span(ctx, WithTitle("compile"))
+				_, test := term.span(ctx, WithTitle("test"))
+				test.startAt = startAt
+				test.End()
+				test.endAt = startAt.Add(time.Second)
+				root.UpdateProgress(0.5)
+
+				term.interrupt()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := vt.NewScreen(tt.width, tt.height)
+			term := NewTerminal(append([]OptsInitializer{WithOutput(screen, screen.Size)}, tt.opts...)...)
+			term.setActive(true)
+			term.setRecording(true)
+
+			tt.fill(term)
+			term.update()
+
+			assertGolden(t, tt.name, screen.String())
+		})
+	}
+}
+
+// fixedClock is always at same time
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func (c fixedClock) NewTicker(d time.Duration) Ticker {
+	return newSystemClock().NewTicker(d)
+}
+
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", "golden", name+".txt")
+
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(got+"\n"), 0o644))
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "golden frame not exist, run tests with -update flag")
+
+	assert.Equal(t, string(expected), got+"\n")
+}
+
+func TestTerminal_update_resize(t *testing.T) {
+	screen := vt.NewScreen(60, 10)
+	term := NewTerminal(WithOutput(screen, screen.Size))
+	term.setActive(true)
+	term.setRecording(true)
+
+	ctx, root := term.span(context.Background(), WithTitle("build"))
+	root.Write("log line, that is wider than resized terminal")
+	_, _ = term.span(ctx, WithTitle("compile"))
+
+	term.update()
+
+	screen.Resize(30, 10) // custom size func, without any resize signal
+	term.update()
+
+	assertGolden(t, "resized", screen.String())
+}
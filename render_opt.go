@@ -15,6 +15,9 @@ type (
 		progressZeroLabel string
 		logsMaxLength     int
 		logsPrefix        string
+		relevance         []SpanFilter
+
+		history *historyStore // durations of previous runs, nil when disabled
 	}
 
 	RenderOptInitializer func(*renderOpts)
@@ -27,6 +30,7 @@ var defaultRenderOpts = renderOpts{
 	progressZeroLabel: RenderOptDefaultProgressZeroLabel,
 	logsMaxLength:     RenderOptDefaultLogsMaxLength,
 	logsPrefix:        RenderOptDefaultLogsPrefix,
+	relevance:         defaultSpanRelevance,
 }
 
 // WithRenderOptSpanMaxRoots set maximum span tasks to display (1 level)
@@ -78,3 +82,13 @@ func WithRenderOptLogsPrefix(prefix string) RenderOptInitializer {
 		opts.logsPrefix = prefix
 	}
 }
+
+// WithRenderOptRelevance set chain of filters, used to select most relevant
+// spans, when not all spans fit into display limits (max roots, child, details).
+// Filters applied in order, next filter is used only when spans are equal
+// default = FilterByFinished, FilterByNewest
+func WithRenderOptRelevance(filters ...SpanFilter) RenderOptInitializer {
+	return func(opts *renderOpts) {
+		opts.relevance = filters
+	}
+}
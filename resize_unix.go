@@ -0,0 +1,33 @@
+//go:build !windows
+
+package terminal
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize will notify, when terminal window is resized (SIGWINCH)
+func notifyResize(ctx context.Context, _ terminalSize) *changeNotifier {
+	resized := newChangeNotifier()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+
+	go func() {
+		defer signal.Stop(signals)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				resized.notify()
+			}
+		}
+	}()
+
+	return resized
+}
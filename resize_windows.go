@@ -0,0 +1,40 @@
+//go:build windows
+
+package terminal
+
+import (
+	"context"
+	"time"
+)
+
+// windows console not have SIGWINCH, so size is checked periodically
+const resizePollInterval = time.Millisecond * 250
+
+// notifyResize will notify, when terminal window is resized
+func notifyResize(ctx context.Context, size terminalSize) *changeNotifier {
+	resized := newChangeNotifier()
+
+	go func() {
+		ticker := time.NewTicker(resizePollInterval)
+		defer ticker.Stop()
+
+		lastWidth, lastHeight, _ := size()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				width, height, err := size()
+				if err != nil || (width == lastWidth && height == lastHeight) {
+					continue
+				}
+
+				lastWidth, lastHeight = width, height
+				resized.notify()
+			}
+		}
+	}()
+
+	return resized
+}
@@ -0,0 +1,166 @@
+package terminal
+
+import "time"
+
+// delimiter between span titles, when span is displayed with all ancestors
+const snapshotPathDelimiter = " › "
+
+type (
+	// SpanSnapshot is read-only copy of span state
+	// at some point in time, safe to use after span is changed or ended
+	SpanSnapshot struct {
+		ID       int64           `json:"id"`
+		Title    string          `json:"title"`
+		Depth    int             `json:"depth"`
+		Progress int             `json:"progress"`
+		Finished bool            `json:"finished"`
+		StartAt  time.Time       `json:"startAt"`
+		EndAt    time.Time       `json:"endAt"`
+		Duration time.Duration   `json:"duration"`
+		Error    string          `json:"error,omitempty"`
+		Logs     []string        `json:"logs,omitempty"`
+		Children []*SpanSnapshot `json:"children,omitempty"`
+
+		Attributes map[string]string `json:"attributes,omitempty"`
+
+		Pinned   bool `json:"pinned,omitempty"`
+		Priority int  `json:"priority,omitempty"`
+		Hidden   bool `json:"hidden,omitempty"` // hidden spans are exported, but not rendered
+	}
+)
+
+// Snapshot return copy of all root spans (with all child)
+// in order of creation, running spans will have
+// duration calculated from current time
+func (t *Terminal) Snapshot() []*SpanSnapshot {
+	now := t.opts.clock.Now()
+	snapshots := make([]*SpanSnapshot, 0)
+
+	t.tree.read(func(roots []*Span) {
+		for _, span := range roots {
+			snapshots = append(snapshots, snapshotSpan(span, now))
+		}
+	})
+
+	return snapshots
+}
+
+// snapshotSpan should be called under tree read lock
+func snapshotSpan(span *Span, now time.Time) *SpanSnapshot {
+	endAt := span.endAt
+	if !span.finished {
+		endAt = now
+	}
+
+	snapshot := &SpanSnapshot{
+		ID:       int64(span.id),
+		Title:    span.title,
+		Depth:    int(span.depth),
+		Progress: span.progress,
+		Finished: span.finished,
+		StartAt:  span.startAt,
+		EndAt:    span.endAt,
+		Duration: endAt.Sub(span.startAt),
+		Logs:     append([]string(nil), span.logs...),
+		Children: make([]*SpanSnapshot, 0, len(span.child)),
+		Pinned:   span.pinned,
+		Priority: span.priority,
+		Hidden:   span.hidden,
+	}
+
+	if span.err != nil {
+		snapshot.Error = span.err.Error()
+	}
+
+	if len(span.attributes) > 0 {
+		snapshot.Attributes = make(map[string]string, len(span.attributes))
+
+		for key, value := range span.attributes {
+			snapshot.Attributes[key] = value
+		}
+	}
+
+	for _, child := range span.child {
+		snapshot.Children = append(snapshot.Children, snapshotSpan(child, now))
+	}
+
+	return snapshot
+}
+
+// visibleSnapshots return copy of tree without hidden spans (and theirs child)
+// used by text renderers, exporters use all spans
+func visibleSnapshots(spans []*SpanSnapshot) []*SpanSnapshot {
+	visible := make([]*SpanSnapshot, 0, len(spans))
+
+	for _, span := range spans {
+		if span.Hidden {
+			continue
+		}
+
+		spanCopy := *span
+		spanCopy.Children = visibleSnapshots(span.Children)
+		visible = append(visible, &spanCopy)
+	}
+
+	return visible
+}
+
+// walkSnapshots will call fn for every span in tree (parent first)
+// path contain titles of all span ancestors and span itself
+func walkSnapshots(spans []*SpanSnapshot, fn func(path []string, span *SpanSnapshot)) {
+	var walk func(parent []string, spans []*SpanSnapshot)
+	walk = func(parent []string, spans []*SpanSnapshot) {
+		for _, span := range spans {
+			path := append(append(make([]string, 0, len(parent)+1), parent...), span.Title)
+
+			fn(path, span)
+			walk(path, span.Children)
+		}
+	}
+
+	walk(nil, spans)
+}
+
+// selfDuration is span duration without time spent in child spans
+// parallel child can took more time than parent, in this case self time is 0
+func selfDuration(span *SpanSnapshot) time.Duration {
+	self := span.Duration
+
+	for _, child := range span.Children {
+		self -= child.Duration
+	}
+
+	if self < 0 {
+		return 0
+	}
+
+	return self
+}
+
+// snapshotsTimeRange is time of first span start and last span end
+func snapshotsTimeRange(spans []*SpanSnapshot) (time.Time, time.Time) {
+	runStart, runEnd := time.Time{}, time.Time{}
+
+	for _, span := range spans {
+		if runStart.IsZero() || span.StartAt.Before(runStart) {
+			runStart = span.StartAt
+		}
+		if end := snapshotEnd(span); end.After(runEnd) {
+			runEnd = end
+		}
+	}
+
+	return runStart, runEnd
+}
+
+// snapshotsWallTime is time from first span start to last span end
+func snapshotsWallTime(spans []*SpanSnapshot) time.Duration {
+	runStart, runEnd := snapshotsTimeRange(spans)
+
+	return runEnd.Sub(runStart)
+}
+
+// snapshotEnd is span end time, or snapshot time for running spans
+func snapshotEnd(span *SpanSnapshot) time.Time {
+	return span.StartAt.Add(span.Duration)
+}
@@ -0,0 +1,69 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_visibleSnapshots(t *testing.T) {
+	spans := []*SpanSnapshot{
+		{Title: "build", Children: []*SpanSnapshot{
+			{Title: "compile"},
+			{Title: "telemetry", Hidden: true, Children: []*SpanSnapshot{
+				{Title: "flush"},
+			}},
+		}},
+		{Title: "hidden root", Hidden: true},
+	}
+
+	visible := visibleSnapshots(spans)
+
+	require.Len(t, visible, 1)
+	assert.Equal(t, "build", visible[0].Title)
+	require.Len(t, visible[0].Children, 1)
+	assert.Equal(t, "compile", visible[0].Children[0].Title)
+
+	// source is not modified
+	assert.Len(t, spans, 2)
+	assert.Len(t, spans[0].Children, 2)
+}
+
+func TestTerminal_hiddenSpans_textReports(t *testing.T) {
+	term := NewTerminal(WithHeadless())
+
+	ctx, root := term.StartSpan(context.Background(), "build")
+	hiddenCtx, hidden := term.StartSpan(ctx, "telemetry", WithHidden())
+	_, hiddenChild := term.StartSpan(hiddenCtx, "flush")
+	_, hiddenRoot := term.StartSpan(context.Background(), "hidden root", WithHidden())
+	hiddenChild.End()
+	hidden.End()
+	hiddenRoot.End()
+	root.End()
+
+	spans := term.Snapshot()
+	markdown := bytes.NewBuffer(nil)
+	require.NoError(t, term.WriteMarkdownSummary(markdown))
+
+	reports := map[string]string{
+		"summary":  renderSummary(spans, nil),
+		"timeline": renderTimeline(spans, 60, 10),
+		"markdown": markdown.String(),
+		"analysis": renderAnalysis(spans, 10),
+	}
+
+	for name, report := range reports {
+		t.Run(name, func(t *testing.T) {
+			assert.Contains(t, report, "build")
+
+			for _, title := range []string{"telemetry", "flush", "hidden root"} {
+				assert.NotContains(t, report, title)
+			}
+		})
+	}
+
+	assert.Contains(t, markdown.String(), "1 spans, 0 failed")
+}
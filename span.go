@@ -2,13 +2,9 @@ package terminal
 
 import (
 	"fmt"
-	"sync"
 	"time"
 )
 
-var globalSpanID spanID = 0
-var globalSpanMux sync.Mutex
-
 type (
 	spanID int64
 
@@ -19,46 +15,63 @@ type (
 		depth   depth   // 0 = root, +1 for child
 		logical bool    // span will not store logs, and propagate it next to non-logical parent
 
-		title     string    // span title to display
-		container container // logs container, layout depend on terminal spawner
-		progress  int       // progress in %, 0 .. 100
+		title         string    // span title to display
+		container     container // logs container, layout depend on terminal spawner
+		logs          []string  // latest logs written directly to span, up to maxLogs
+		maxLogs       int       // limit of stored logs, 0 = unlimited
+		progress      int       // progress in %, 0 .. 100
+		finishedChild int       // count of finished child spans
+		err           error     // span failure reason, nil when span is ok
+
+		attributes  map[string]string // key attributes, for reports only
+		pinned      bool              // span is always displayed, even over render limits
+		pinnedChild int               // count of pinned descendants (all depths), parent is displayed with them
+		priority    int               // spans with higher priority are more relevant for display
+		hidden      bool              // span is timed and exported, but never rendered
+		hiddenChild int               // count of hidden child spans
+		observer    SpanObserver      // receive all span changes, can be nil
+		clock       Clock             // source of span timestamps
 
 		changedAt time.Time
 		startAt   time.Time
 		endAt     time.Time
 		finished  bool
 
-		mux sync.RWMutex
+		tree *spanTree // owner of span, all span state is guarded by tree lock
 	}
 )
 
-func newSpan(parent *Span, container container, logical bool) *Span {
-	globalSpanMux.Lock()
-	defer globalSpanMux.Unlock()
-
-	globalSpanID++
+// newSpan should be called under tree write lock
+func newSpan(tree *spanTree, parent *Span, container container, logical bool, clock Clock) *Span {
+	id := tree.nextID()
 
 	span := &Span{
-		id:      globalSpanID,
+		id:      id,
 		parent:  parent,
 		child:   make([]*Span, 0),
 		logical: logical,
 
-		title:     fmt.Sprintf("span #%d", globalSpanID),
+		title:     fmt.Sprintf("span #%d", id),
 		container: container,
 		progress:  0,
 
-		changedAt: time.Now(),
-		startAt:   time.Now(),
+		changedAt: clock.Now(),
+		startAt:   clock.Now(),
 		endAt:     time.Time{},
 		finished:  false,
+
+		clock: clock,
+		tree:  tree,
 	}
 
 	if parent != nil {
 		span.depth = parent.depth + 1
 		parent.child = append(parent.child, span)
+	} else {
+		tree.roots = append(tree.roots, span)
 	}
 
+	tree.touch()
 	return span
 }
 
@@ -68,12 +81,41 @@ func (s *Span) Write(src string) {
 		return
 	}
 
-	s.mux.Lock()
-	defer s.mux.Unlock()
+	s.tree.mux.Lock()
+	defer s.tree.mux.Unlock()
+
+	s.write(src)
+}
+
+func (s *Span) write(src string) {
+	s.storeLog(src)
+	s.emit(SpanEventWrite, src)
+	s.display(src)
+}
+
+// storeLog keep only latest maxLogs lines
+// oldest lines are dropped without copy, lines are moved
+// to new array only when append is out of capacity (amortized)
+func (s *Span) storeLog(src string) {
+	if s.maxLogs > 0 && len(s.logs) >= s.maxLogs {
+		s.logs = s.logs[len(s.logs)-s.maxLogs+1:]
+	}
+
+	s.logs = append(s.logs, src)
+}
+
+// display will show line in container of nearest physical span
+// line is not stored in parents logs
+func (s *Span) display(src string) {
+	if s.hidden {
+		// logs of hidden span is never rendered,
+		// so terminal should not be redrawn
+		return
+	}
 
 	if s.logical {
 		// propagate next to physical parent
-		s.parent.Write(src)
+		s.parent.display(src)
 		return
 	}
 
@@ -88,8 +130,8 @@ func (s *Span) UpdateProgress(progress float64) {
 		return
 	}
 
-	s.mux.Lock()
-	defer s.mux.Unlock()
+	s.tree.mux.Lock()
+	defer s.tree.mux.Unlock()
 
 	if s.finished {
 		return
@@ -104,9 +146,47 @@ func (s *Span) UpdateProgress(progress float64) {
 	}
 
 	s.progress = int(progress * 100)
+	s.emit(SpanEventProgress, "")
+	s.propagateChange()
+}
+
+// SetError mark this span as failed
+// error will be displayed and exported with span
+// nil error will reset span status back to ok
+func (s *Span) SetError(err error) {
+	if s == nil {
+		return
+	}
+
+	s.tree.mux.Lock()
+	defer s.tree.mux.Unlock()
+
+	s.err = err
+	s.emit(SpanEventError, "")
 	s.propagateChange()
 }
 
+// SetAttribute set key attribute of span (like file name, package, etc..)
+// attributes is not displayed in terminal, but used in reports
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+
+	s.tree.mux.Lock()
+	defer s.tree.mux.Unlock()
+
+	s.setAttribute(key, value)
+}
+
+func (s *Span) setAttribute(key, value string) {
+	if s.attributes == nil {
+		s.attributes = make(map[string]string)
+	}
+
+	s.attributes[key] = value
+}
+
 // End will close this span
 // It will ignore all other method calls to this span
 // also time took will be calculated after span ending
@@ -116,35 +196,68 @@ func (s *Span) End() {
 		return
 	}
 
-	s.mux.Lock()
-	defer s.mux.Unlock()
+	s.tree.mux.Lock()
+	defer s.tree.mux.Unlock()
+
+	s.end()
+}
 
+func (s *Span) end() {
 	if s.finished {
 		return
 	}
 
 	for _, subSpan := range s.child {
-		subSpan.End()
+		subSpan.end()
 	}
 
 	s.progress = 100
 	s.finished = true
-	s.endAt = time.Now()
+	s.endAt = s.now()
+	s.tree.countFinished(s)
 	s.container = newEmptyContainer()
-	s.propagateChange()
+	s.emit(SpanEventEnd, "")
+	s.tree.touch()
 }
 
+// propagateChange will mark span and all its parents as changed
+// should be called under tree write lock
 func (s *Span) propagateChange() {
-	if s == nil {
-		return
+	now := s.now()
+	s.tree.touch()
+
+	for span := s; span != nil && !span.finished; span = span.parent {
+		span.changedAt = now
 	}
-	if s.finished {
-		return
+}
+
+// titlePath is titles of all span ancestors and span itself
+func (s *Span) titlePath() []string {
+	path := make([]string, 0, s.depth+1)
+
+	for span := s; span != nil; span = span.parent {
+		path = append([]string{span.title}, path...)
 	}
 
-	s.changedAt = time.Now()
+	return path
+}
 
-	if s.parent != nil {
-		s.parent.propagateChange()
+func (s *Span) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
 	}
+
+	return s.clock.Now()
+}
+
+// isPinned is true, when span or any of its descendants is pinned
+// should be called under tree read lock
+func (s *Span) isPinned() bool {
+	return s.pinned || s.pinnedChild > 0
+}
+
+// cancelling is true for running spans, when terminal context is canceled
+// should be called under tree read lock
+func (s *Span) cancelling() bool {
+	return !s.finished && s.tree != nil && s.tree.cancelling
 }
@@ -23,3 +23,34 @@ func WithInitialProgress(initialProgress float64) StartOpt {
 		span.progress = int(initialProgress * 100)
 	}
 }
+
+func WithAttribute(key, value string) StartOpt {
+	return func(span *Span) {
+		span.setAttribute(key, value)
+	}
+}
+
+// WithPinned will always display span, even when
+// there are more spans than render limits
+func WithPinned() StartOpt {
+	return func(span *Span) {
+		span.pinned = true
+	}
+}
+
+// WithPriority set display priority of span, spans with higher
+// priority are displayed first, when not all spans fit into render limits
+// default = 0
+func WithPriority(priority int) StartOpt {
+	return func(span *Span) {
+		span.priority = priority
+	}
+}
+
+// WithHidden will never render span (and its child) in terminal and
+// text summaries, but span is still timed and exported (json, junit, pprof, ..)
+func WithHidden() StartOpt {
+	return func(span *Span) {
+		span.hidden = true
+	}
+}
@@ -0,0 +1,111 @@
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpan_Write_logs(t *testing.T) {
+	term := NewTerminal(WithHeadless(), WithSpanMaxLogs(3))
+
+	ctx, root := term.StartSpan(context.Background(), "root")
+	ctx, child := term.StartSpan(ctx, "child")
+	_, details := term.StartSpan(ctx, "details")
+
+	root.Write("root log")
+	for i := 0; i < 5; i++ {
+		details.Write(fmt.Sprintf("details %d", i))
+	}
+	child.Write("child log")
+
+	snapshot := term.Snapshot()
+	require.Len(t, snapshot, 1)
+
+	// only direct writes are stored, and only latest lines
+	assert.Equal(t, []string{"root log"}, snapshot[0].Logs)
+	assert.Equal(t, []string{"child log"}, snapshot[0].Children[0].Logs)
+	assert.Equal(t, []string{"details 2", "details 3", "details 4"}, snapshot[0].Children[0].Children[0].Logs)
+
+	// but all logs are displayed in root container
+	term.tree.read(func(_ []*Span) {
+		assert.Equal(t, []string{"details 2", "details 3", "details 4", "child log"}, root.container.content())
+	})
+
+	// many writes keep latest lines in order, and stored lines are bounded
+	for i := 5; i < 1000; i++ {
+		details.Write(fmt.Sprintf("details %d", i))
+	}
+
+	assert.Equal(t, []string{"details 997", "details 998", "details 999"}, term.Snapshot()[0].Children[0].Children[0].Logs)
+	term.tree.read(func(_ []*Span) {
+		assert.LessOrEqual(t, cap(details.logs), 8, "dropped lines should not be kept")
+	})
+}
+
+func TestSpan_hidden(t *testing.T) {
+	term := NewTerminal(WithHeadless())
+
+	ctx, root := term.StartSpan(context.Background(), "root")
+	hiddenCtx, hidden := term.StartSpan(ctx, "telemetry", WithHidden())
+	_, hiddenChild := term.StartSpan(hiddenCtx, "flush", WithHidden())
+	_, _ = term.StartSpan(context.Background(), "hidden root", WithHidden())
+
+	// hidden span is not rendered, so write should not redraw terminal
+	takeChange(term) // started spans
+	hidden.Write("hidden log")
+	hiddenChild.Write("hidden child log")
+	assert.False(t, takeChange(term), "write to hidden span notify about change")
+
+	hiddenChild.End()
+	hidden.End()
+
+	term.tree.read(func(roots []*Span) {
+		assert.Equal(t, 1, root.hiddenChild)
+		assert.Equal(t, 0, root.finishedChild, "hidden child is not counted as finished")
+		assert.Equal(t, 1, hidden.hiddenChild)
+		assert.Equal(t, 0, hidden.finishedChild)
+		assert.Equal(t, 1, term.tree.hiddenRoots)
+
+		for _, line := range renderSpanWithOptions(root, term.opts.renderOpts) {
+			assert.NotContains(t, line.text, "more", "hidden spans is not counted")
+			assert.NotContains(t, line.text, "hidden", "hidden spans is not rendered")
+		}
+	})
+
+	// hidden span is still exported
+	snapshot := term.Snapshot()
+	require.Len(t, snapshot, 2)
+	require.Len(t, snapshot[0].Children, 1)
+	assert.True(t, snapshot[0].Children[0].Hidden)
+	assert.Equal(t, []string{"hidden log"}, snapshot[0].Children[0].Logs)
+	require.Len(t, snapshot[0].Children[0].Children, 1)
+	assert.Equal(t, []string{"hidden child log"}, snapshot[0].Children[0].Children[0].Logs)
+}
+
+// takeChange will receive pending change notification, if any
+func takeChange(term *Terminal) bool {
+	select {
+	case <-term.tree.changes.changed():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestTerminal_WithClock_nil(t *testing.T) {
+	term := NewTerminal(WithHeadless(), WithClock(nil))
+
+	assert.NotPanics(t, func() {
+		_, span := term.StartSpan(context.Background(), "build")
+		span.Write("log")
+		span.End()
+	})
+
+	snapshot := term.Snapshot()
+	require.Len(t, snapshot, 1)
+	assert.False(t, snapshot[0].StartAt.IsZero(), "system clock is used")
+}
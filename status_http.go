@@ -0,0 +1,238 @@
+package terminal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// live page will receive new state not often than this interval
+const statusEventsInterval = time.Millisecond * 250
+
+type (
+	statusServer struct {
+		server *http.Server
+		addr   string // listen address, known after start
+		done   chan struct{}
+	}
+
+	statusReport struct {
+		Active    bool            `json:"active"`
+		UpdatedAt time.Time       `json:"updatedAt"`
+		Spans     []*SpanSnapshot `json:"spans"`
+	}
+)
+
+func newStatusServer(addr string, t *Terminal) *statusServer {
+	done := make(chan struct{})
+
+	return &statusServer{
+		server: &http.Server{
+			Addr:    addr,
+			Handler: t.statusHandler(done),
+		},
+		done: done,
+	}
+}
+
+func (s *statusServer) start() {
+	// listen synchronously, so bind errors is reported
+	// and address is known right after start
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "failed serve http status: %v\n", err)
+		return
+	}
+
+	s.addr = listener.Addr().String()
+
+	go func() {
+		err := s.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			_, _ = fmt.Fprintf(os.Stderr, "failed serve http status: %v\n", err)
+		}
+	}()
+}
+
+func (s *statusServer) stop() {
+	// live streams is never idle, so instead of graceful shutdown
+	// we signal all streams to finish and close connections
+	close(s.done)
+	_ = s.server.Close()
+}
+
+func (t *Terminal) statusHandler(done <-chan struct{}) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(statusPage))
+	})
+
+	mux.HandleFunc("/spans.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(t.statusJSON())
+	})
+
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		ticker := time.NewTicker(statusEventsInterval)
+		defer ticker.Stop()
+
+		var latest []byte
+
+		for {
+			// send only when something changed
+			current := t.statusJSON()
+			if !bytes.Equal(current, latest) {
+				latest = current
+
+				_, _ = fmt.Fprintf(w, "data: %s\n\n", current)
+				flusher.Flush()
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+
+	return mux
+}
+
+func (t *Terminal) statusJSON() []byte {
+	spans := t.Snapshot()
+
+	// duration of running spans changes every call,
+	// so stream will be updated only when have something running
+	// or tree is changed
+	data, err := json.Marshal(statusReport{
+		Active:    t.isActive(),
+		UpdatedAt: latestSnapshotChange(spans),
+		Spans:     spans,
+	})
+	if err != nil {
+		return []byte("{}")
+	}
+
+	return data
+}
+
+func latestSnapshotChange(spans []*SpanSnapshot) time.Time {
+	latest := time.Time{}
+
+	for _, span := range spans {
+		if span.StartAt.After(latest) {
+			latest = span.StartAt
+		}
+		if span.EndAt.After(latest) {
+			latest = span.EndAt
+		}
+
+		if child := latestSnapshotChange(span.Children); child.After(latest) {
+			latest = child
+		}
+	}
+
+	return latest
+}
+
+const statusPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>spans</title>
+<style>
+  body { font-family: monospace; background: #111; color: #ddd; margin: 1em 2em; }
+  ul { list-style: none; padding-left: 1.5em; margin: 0; }
+  li { margin: 2px 0; }
+  .running { color: #e5c07b; font-weight: bold; }
+  .finished { color: #98c379; }
+  .root { color: #61afef; font-weight: bold; }
+  .failed { color: #e06c75; font-weight: bold; }
+  .logs { color: #c678dd; white-space: pre; padding-left: 1.5em; }
+  .time { display: inline-block; min-width: 5em; }
+  #state { color: #888; }
+</style>
+</head>
+<body>
+<div id="state">connecting..</div>
+<ul id="spans"></ul>
+<script>
+  function duration(ns) {
+    var ms = ns / 1e6;
+    if (ms < 1000) return Math.round(ms) + "ms";
+    if (ms < 300000) return Math.round(ms / 1000) + "s";
+    if (ms < 3600000) return Math.round(ms / 60000) + "m";
+    return Math.round(ms / 3600000) + "h";
+  }
+
+  function visible(spans) {
+    return spans.filter(function (span) { return !span.hidden; });
+  }
+
+  function render(span) {
+    var li = document.createElement("li");
+    var line = document.createElement("div");
+    var status = span.finished ? duration(span.duration) : (span.progress > 0 ? span.progress + "%" : "...");
+
+    line.className = span.depth === 0 ? "root" : (span.finished ? "finished" : "running");
+    if (span.error) line.className = "failed";
+    line.innerHTML = "<span class=\"time\"></span>";
+    line.firstChild.textContent = status;
+    line.appendChild(document.createTextNode(span.title + (span.error ? ": " + span.error : "")));
+    li.appendChild(line);
+
+    if (span.logs && span.logs.length && !span.finished) {
+      var logs = document.createElement("div");
+      logs.className = "logs";
+      logs.textContent = span.logs.slice(-4).join("\n");
+      li.appendChild(logs);
+    }
+
+    if (span.children && span.children.length) {
+      var ul = document.createElement("ul");
+      visible(span.children).forEach(function (child) { ul.appendChild(render(child)); });
+      li.appendChild(ul);
+    }
+
+    return li;
+  }
+
+  function update(report) {
+    var root = document.getElementById("spans");
+    root.innerHTML = "";
+    visible(report.spans || []).forEach(function (span) { root.appendChild(render(span)); });
+    document.getElementById("state").textContent = report.active ? "running" : "finished";
+  }
+
+  var events = new EventSource("events");
+  events.onmessage = function (e) { update(JSON.parse(e.data)); };
+  events.onerror = function () { document.getElementById("state").textContent = "disconnected"; };
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,125 @@
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_statusHandler(t *testing.T) {
+	term := NewTerminal()
+	term.setActive(true)
+	term.setRecording(true)
+
+	ctx, root := term.span(context.Background(), WithTitle("build"))
+	_, child := term.span(ctx, WithTitle("compile"))
+	child.UpdateProgress(0.5)
+	root.Write("compiling..")
+
+	done := make(chan struct{})
+	defer close(done)
+
+	server := httptest.NewServer(term.statusHandler(done))
+	defer server.Close()
+
+	t.Run("json", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/spans.json")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		report := statusReport{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+		require.Len(t, report.Spans, 1)
+		assert.True(t, report.Active)
+		assert.Equal(t, "build", report.Spans[0].Title)
+		assert.Equal(t, []string{"compiling.."}, report.Spans[0].Logs)
+		require.Len(t, report.Spans[0].Children, 1)
+		assert.Equal(t, "compile", report.Spans[0].Children[0].Title)
+		assert.Equal(t, 50, report.Spans[0].Children[0].Progress)
+	})
+
+	t.Run("page", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	})
+
+	t.Run("events", func(t *testing.T) {
+		reqCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, server.URL+"/events", nil)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(line, "data: "))
+
+		report := statusReport{}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &report))
+		require.Len(t, report.Spans, 1)
+		assert.Equal(t, "build", report.Spans[0].Title)
+	})
+}
+
+func TestTerminal_statusServer_withoutTerminalOutput(t *testing.T) {
+	fetch := func(t *testing.T, term *Terminal) statusReport {
+		term.mux.RLock()
+		require.NotNil(t, term.statusServer, "status server should be started")
+		addr := term.statusServer.addr
+		term.mux.RUnlock()
+
+		resp, err := http.Get("http://" + addr + "/spans.json")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		report := statusReport{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+		return report
+	}
+
+	t.Run("not terminal output", func(t *testing.T) {
+		term := NewTerminal(WithHTTPStatus("127.0.0.1:0"), WithoutSignalHandling())
+		term.isANSITerminal = false // like in CI
+
+		term.Capture()
+		_, span := term.StartSpan(context.Background(), "build")
+		span.End()
+
+		report := fetch(t, term)
+		require.Len(t, report.Spans, 1)
+		assert.Equal(t, "build", report.Spans[0].Title)
+
+		term.Release()
+		assert.Nil(t, term.statusServer)
+	})
+
+	t.Run("headless", func(t *testing.T) {
+		term := NewTerminal(WithHeadless(), WithHTTPStatus("127.0.0.1:0"))
+		defer term.Release()
+
+		_, _ = term.StartSpan(context.Background(), "deploy")
+
+		report := fetch(t, term)
+		require.Len(t, report.Spans, 1)
+		assert.Equal(t, "deploy", report.Spans[0].Title)
+	})
+}
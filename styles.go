@@ -2,14 +2,20 @@ package terminal
 
 import "github.com/charmbracelet/lipgloss"
 
+const colorRed = "1"
 const colorGreen = "2"
 const colorYellow = "3"
 const colorCyan = "4"
 const colorPurple = "5"
+const colorGray = "8"
 
 var styleStatusDone = lipgloss.NewStyle().
 	Foreground(lipgloss.Color(colorGreen))
 
+var styleStatusFailed = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color(colorRed))
+
 var styleStatusActive = lipgloss.NewStyle().
 	Bold(true).
 	Foreground(lipgloss.Color(colorYellow))
@@ -20,3 +26,9 @@ var styleHeader = lipgloss.NewStyle().
 
 var styleLogs = lipgloss.NewStyle().
 	Foreground(lipgloss.Color(colorPurple))
+
+var styleHistory = lipgloss.NewStyle().
+	Foreground(lipgloss.Color(colorGray))
+
+var styleCancelling = lipgloss.NewStyle().
+	Foreground(lipgloss.Color(colorRed))
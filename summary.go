@@ -0,0 +1,109 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	summaryIconDone    = "✔"
+	summaryIconFailed  = "✘"
+	summaryIconRunning = "…"
+)
+
+// WriteSummary will write full span tree (all depths)
+// with statuses, durations, errors and run totals
+func (t *Terminal) WriteSummary(w io.Writer) error {
+	_, err := io.WriteString(w, renderSummary(t.Snapshot(), t.history))
+	return err
+}
+
+func (t *Terminal) printReleaseSummary(position SummaryPosition) {
+	if t.opts.summaryPosition != position {
+		return
+	}
+
+	spans := t.Snapshot()
+
+	_, _ = io.WriteString(t.realStdout, "\n")
+	_, _ = io.WriteString(t.realStdout, renderSummary(spans, t.history))
+
+	if t.opts.summaryAnalysis {
+		_, _ = io.WriteString(t.realStdout, renderAnalysis(spans, t.opts.summarySlowest))
+	}
+
+	if t.opts.summaryTimeline {
+		_, _ = io.WriteString(t.realStdout, renderTimeline(spans, t.realStdoutWidth(), t.opts.timelineMaxDepth))
+	}
+}
+
+func renderSummary(spans []*SpanSnapshot, history *historyStore) string {
+	spans = visibleSnapshots(spans)
+	out := strings.Builder{}
+	regressions := make([]string, 0)
+
+	total, failed := 0, 0
+	walkSnapshots(spans, func(path []string, span *SpanSnapshot) {
+		total++
+		if span.Error != "" {
+			failed++
+		}
+
+		line := renderSummaryLine(span)
+
+		if change, exist := history.compare(path, span.Duration); exist && span.Finished {
+			changeStyle := styleHistory
+			if history.isRegression(span.Duration, change) {
+				changeStyle = styleStatusFailed
+				regressions = append(regressions, fmt.Sprintf("%5s %s (%s)",
+					renderSnapshotDuration(span),
+					historyKey(path),
+					renderHistoryChange(change),
+				))
+			}
+
+			line += " " + changeStyle.Render(renderHistoryChange(change))
+		}
+
+		out.WriteString(line + "\n")
+	})
+
+	totals := fmt.Sprintf("%d spans, %d failed, wall time %s",
+		total,
+		failed,
+		renderTook(snapshotsWallTime(spans)),
+	)
+
+	if len(regressions) > 0 {
+		totals += fmt.Sprintf(", %d regressions", len(regressions))
+
+		out.WriteString(styleStatusFailed.Render("regressions:") + "\n")
+		for _, regression := range regressions {
+			out.WriteString("  " + regression + "\n")
+		}
+	}
+
+	if failed > 0 || len(regressions) > 0 {
+		out.WriteString(styleStatusFailed.Render(totals) + "\n")
+	} else {
+		out.WriteString(styleHeader.Render(totals) + "\n")
+	}
+
+	return out.String()
+}
+
+func renderSummaryLine(span *SpanSnapshot) string {
+	padding := strings.Repeat("  ", span.Depth)
+	content := fmt.Sprintf("%5s %s", renderSnapshotDuration(span), span.Title)
+
+	if span.Error != "" {
+		return padding + styleStatusFailed.Render(summaryIconFailed+" "+content+": "+span.Error)
+	}
+
+	if !span.Finished {
+		return padding + styleStatusActive.Render(summaryIconRunning+" "+content)
+	}
+
+	return padding + styleStatusDone.Render(summaryIconDone+" "+content)
+}
@@ -0,0 +1,43 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal_WriteSummary_golden(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	require.NoError(t, newExportTestTerminal().WriteSummary(buf))
+
+	// styles depend on color profile of output, so only text is compared
+	assertGolden(t, "summary", stripANSI(buf.String()))
+}
+
+func TestTerminal_Release_summaryWithoutTerminalOutput(t *testing.T) {
+	output := bytes.NewBuffer(nil)
+	term := NewTerminal(
+		WithOutput(output, fixedSize(80, 20)),
+		WithReleaseSummary(SummaryAfterStdout),
+		WithReleaseSummaryAnalysis(3),
+		WithReleaseSummaryTimeline(1),
+		WithoutSignalHandling(),
+	)
+	term.isANSITerminal = false // like in CI, or stdout piped
+
+	term.Capture()
+	_, span := term.StartSpan(context.Background(), "build")
+	span.End()
+
+	term.Release()
+	term.Release() // printed only once
+
+	report := stripANSI(output.String())
+	assert.Equal(t, 1, strings.Count(report, "1 spans, 0 failed"))
+	assert.Contains(t, report, "critical path")
+	assert.Contains(t, report, "build |")
+}
@@ -8,68 +8,179 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 )
 
-// logs will be updated at least once per interval
-const forceUpdateInterval = time.Millisecond * 50
+// running spans with estimated progress will be redrawn at least
+// once per interval, because estimate is changed with time
+const estimateRefreshInterval = time.Second
 
-// pause between normal updates
-const pauseBetweenUpdates = time.Millisecond * 5
+// used when real terminal size is unknown
+const defaultTerminalWidth = 80
 
 type Terminal struct {
 	opts terminalOpts
 
 	isANSITerminal bool
-	rootSpans      []*Span
-	active         bool
+	tree           *spanTree
+	changes        *changeNotifier
+	active         int32 // atomic, 1 when output is captured
+	recording      int32 // atomic, 1 between Capture and Release (even when output is not captured)
+	reported       bool  // release reports (markdown, history) is written
 	watchCtx       context.Context
 	watchCancel    func()
 
-	stdoutBuffer  *bytes.Buffer
-	realStdout    *os.File
-	termOs        *termOS
-	logsContainer container
-	watchFinished chan struct{}
-
-	mux sync.RWMutex
+	stdoutBuffer   *bytes.Buffer
+	realStdout     io.Writer
+	realStderr     io.Writer
+	realStdoutSize terminalSize
+	termOs         *termOS
+	logsContainer  container
+	watchFinished  chan struct{}
+	stdoutDrained  <-chan struct{} // closed, when all captured stdout is read
+	statusServer   *statusServer
+	history        *historyStore
+	stopSignals    func()
+	rootCtx        context.Context
+	rootCancel     func()
+
+	stdoutMux sync.Mutex // guard stdoutBuffer and logsContainer
+	mux       sync.RWMutex
 }
 
 func NewTerminal(initializers ...OptsInitializer) *Terminal {
 	opt := &terminalOpts{
 		containerMaxLines: OptDefaultContainerMaxLines,
 		stdoutMaxLines:    OptDefaultStdoutMaxLines,
+		spanMaxLogs:       OptDefaultSpanMaxLogs,
 		renderOpts:        defaultRenderOpts,
+		maxFPS:            OptDefaultMaxFPS,
+		clock:             newSystemClock(),
+
+		historyRegressionThreshold: OptDefaultHistoryRegressionThreshold,
 	}
 	for _, initializer := range initializers {
 		initializer(opt)
 	}
 
-	return &Terminal{
+	if opt.maxFPS <= 0 {
+		opt.maxFPS = OptDefaultMaxFPS
+	}
+
+	history := newTerminalHistory(opt)
+	opt.renderOpts.history = history
+
+	isANSITerminal := termenv.ColorProfile() != termenv.Ascii
+	output, outputSize := io.Writer(os.Stdout), fileTerminalSize(os.Stdout)
+
+	if opt.output != nil {
+		// custom output is always rendered
+		isANSITerminal = true
+		output, outputSize = opt.output, opt.outputSize
+	}
+
+	changes := newChangeNotifier()
+
+	t := &Terminal{
 		opts: *opt,
 
-		isANSITerminal: termenv.ColorProfile() != termenv.Ascii,
-		rootSpans:      make([]*Span, 0),
-		active:         false,
+		isANSITerminal: isANSITerminal,
+		tree:           newSpanTree(changes),
+		changes:        changes,
+		active:         0,
+
+		stdoutBuffer:   bytes.NewBuffer(nil),
+		realStdout:     output,
+		realStderr:     os.Stderr,
+		realStdoutSize: outputSize,
+		termOs:         newTermOs(output, outputSize),
+		logsContainer:  newMultiLineContainer(opt.stdoutMaxLines),
+		history:        history,
+	}
+
+	t.rootCtx, t.rootCancel = context.WithCancel(ContextWithTerminal(context.Background(), t))
+
+	if opt.headless {
+		// headless terminal record spans without capture
+		t.startStatusServer()
+	}
+
+	if opt.gracefulInterrupt {
+		// root context can be used without capture (non TTY, CI),
+		// so interrupt is handled for whole terminal lifetime
+		t.handleSignals()
+	}
+
+	return t
+}
+
+// Context is root context of terminal, spans started from it will be
+// created in this terminal. With WithGracefulInterrupt option this context
+// is cancelled on first interrupt (Ctrl+C)
+func (t *Terminal) Context() context.Context {
+	return t.rootCtx
+}
+
+// interrupt will cancel root context, and show in live view
+// that running spans are winding down
+func (t *Terminal) interrupt() {
+	t.rootCancel()
+	t.tree.cancel()
+}
+
+// first interrupt is graceful, when it is enabled by option
+func (t *Terminal) isGracefulInterrupt() bool {
+	return t.opts.gracefulInterrupt && !t.tree.isCancelling()
+}
+
+func newTerminalHistory(opt *terminalOpts) *historyStore {
+	path := opt.historyFile
+
+	if path == "" && opt.historyName != "" {
+		var err error
+
+		path, err = historyFilePath(opt.historyName)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "history disabled: %v\n", err)
+			return nil
+		}
+	}
+
+	if path == "" {
+		return nil
+	}
 
-		stdoutBuffer:  bytes.NewBuffer(nil),
-		realStdout:    os.Stdout,
-		termOs:        newTermOs(os.Stdout),
-		logsContainer: newMultiLineContainer(opt.stdoutMaxLines),
+	history := newHistoryStore(path, opt.historyRegressionThreshold)
+	if err := history.load(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "history disabled: %v\n", err)
+		return nil
 	}
+
+	return history
 }
 
-func (t *Terminal) capture() {
+// Capture will capture control on output to stdout/stderr
+// and display custom logs from spans of this terminal
+// all other print/logs will be redirected and printed in special
+// region alongside span logs
+func (t *Terminal) Capture() {
 	t.mux.Lock()
 	defer t.mux.Unlock()
 
-	if t.active {
+	if t.isRecording() {
 		return
 	}
 
+	// spans are recorded for reports, even when output
+	// is not terminal (CI, pipes, nohup, ..)
+	t.setRecording(true)
+	t.reported = false
+	t.startStatusServer()
+
 	if !t.isANSITerminal {
 		return
 	}
@@ -84,13 +195,14 @@ func (t *Terminal) capture() {
 	t.stdoutBuffer.Reset()
 	t.watchFinished = make(chan struct{}) // this channel will be closed, after watch is completed
 
-	t.active = true
-	go t.redirectAllStdoutToContainer()
+	t.setActive(true)
+	t.redirectAllStdoutToContainer()
+	t.handleSignals()
 	go t.watch()
 }
 
 func (t *Terminal) redirectAllStdoutToContainer() {
-	bufioStdout(t.watchCtx, func(message bufioMessage) {
+	t.stdoutDrained = bufioStdout(t.watchCtx, func(message bufioMessage) {
 		if message.err != nil {
 			if !errors.Is(message.err, io.EOF) {
 				_, _ = fmt.Fprint(os.Stderr, fmt.Sprintf("failed buffer stdout: %v", message.err))
@@ -99,35 +211,113 @@ func (t *Terminal) redirectAllStdoutToContainer() {
 			return
 		}
 
+		t.stdoutMux.Lock()
+		defer t.stdoutMux.Unlock()
+
 		t.stdoutBuffer.Write(message.data)
 		t.stdoutBuffer.WriteString("\n")
 		t.logsContainer.write(string(message.data))
+		t.changes.notify()
 	})
 }
 
-func (t *Terminal) release() {
+// Release will stop terminal for outputting span logs
+// release stdout to default control
+// all span updates in released mode, may be ignored
+func (t *Terminal) Release() {
 	t.mux.Lock()
 	defer t.mux.Unlock()
 
-	if !t.active {
+	// signals can be handled without capture (graceful interrupt),
+	// so handler is always stopped, before signal is re-raised
+	t.stopHandleSignals()
+	t.stopStatusServer()
+
+	recorded := t.isRecording() || (t.opts.headless && t.hasSpans())
+	if !recorded || t.reported {
 		return
 	}
 
-	t.active = false
+	t.setRecording(false)
+
+	if t.isActive() {
+		t.setActive(false)
+		t.watchCancel()
+
+		// wait for watch is finished gracefully
+		<-t.watchFinished
+	} else if t.opts.summaryPosition != SummaryNone {
+		// output is not captured (CI, pipes, headless), so there is
+		// no watch, that print summary around captured stdout
+		t.printReleaseSummary(t.opts.summaryPosition)
+	}
+
+	t.reported = true
+	t.writeReleaseMarkdownSummary()
+	t.saveHistory()
+}
+
+// should be called under terminal lock
+func (t *Terminal) startStatusServer() {
+	if t.opts.httpStatusAddr == "" || t.statusServer != nil {
+		return
+	}
+
+	t.statusServer = newStatusServer(t.opts.httpStatusAddr, t)
+	t.statusServer.start()
+}
 
-	time.Sleep(time.Millisecond * 500) // wait for all io term events done
-	t.watchCancel()
+// should be called under terminal lock
+func (t *Terminal) stopStatusServer() {
+	if t.statusServer == nil {
+		return
+	}
+
+	t.statusServer.stop()
+	t.statusServer = nil
+}
+
+func (t *Terminal) hasSpans() bool {
+	exist := false
+	t.tree.read(func(roots []*Span) {
+		exist = len(roots) > 0
+	})
+
+	return exist
+}
+
+func (t *Terminal) saveHistory() {
+	if t.history == nil {
+		return
+	}
+
+	t.history.record(t.Snapshot())
+
+	if err := t.history.save(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+}
 
-	// wait for watch is finished gracefully
-	<-t.watchFinished
+// StartSpan will extract parent Span from context (if exist) and create new Span
+// in this terminal, new Span will be written to new context
+// In case when terminal is not active, Span can be nil, but it is totally safe to use
+func (t *Terminal) StartSpan(ctx context.Context, title string, opts ...StartOpt) (context.Context, *Span) {
+	return t.span(ctx, append([]StartOpt{WithTitle(title)}, opts...)...)
 }
 
 func (t *Terminal) span(ctx context.Context, opts ...StartOpt) (context.Context, *Span) {
-	if !t.active {
+	if !t.isRecording() && !t.opts.headless {
 		return ctx, nil
 	}
 
+	t.tree.mux.Lock()
+	defer t.tree.mux.Unlock()
+
 	parent := spanFromContext(ctx)
+	if parent != nil && parent.tree != t.tree {
+		// parent from other terminal, span will be root in this terminal
+		parent = nil
+	}
 
 	currentDepth := depth(0)
 	if parent != nil {
@@ -135,106 +325,188 @@ func (t *Terminal) span(ctx context.Context, opts ...StartOpt) (context.Context,
 	}
 
 	newSpan := newSpan(
+		t.tree,
 		parent,
 		newContainer(currentDepth, t.opts.containerMaxLines),
 		!currentDepth.isRoot(),
+		t.opts.clock,
 	)
 
 	for _, enrich := range opts {
 		enrich(newSpan)
 	}
 
-	if currentDepth == depth(0) {
-		t.rootSpans = append(t.rootSpans, newSpan)
-	}
+	t.tree.countHidden(newSpan)
+	t.tree.countPinned(newSpan)
+	newSpan.maxLogs = t.opts.spanMaxLogs
+
+	newSpan.observer = t.opts.spanObserver
+	newSpan.emit(SpanEventStart, "")
 
 	return contextWithSpan(ctx, newSpan), newSpan
 }
 
+func (t *Terminal) isRecording() bool {
+	return atomic.LoadInt32(&t.recording) == 1
+}
+
+func (t *Terminal) setRecording(recording bool) {
+	if recording {
+		atomic.StoreInt32(&t.recording, 1)
+		return
+	}
+
+	atomic.StoreInt32(&t.recording, 0)
+}
+
+func (t *Terminal) isActive() bool {
+	return atomic.LoadInt32(&t.active) == 1
+}
+
+func (t *Terminal) setActive(active bool) {
+	if active {
+		atomic.StoreInt32(&t.active, 1)
+		return
+	}
+
+	atomic.StoreInt32(&t.active, 0)
+}
+
+// watch will redraw terminal only when something is changed
+// frames are limited by max fps, all changes between frames
+// will be rendered together in next frame
 func (t *Terminal) watch() {
-	watching := true
+	clock := t.opts.clock
+	frameInterval := time.Second / time.Duration(t.opts.maxFPS)
 
-	ticker := time.NewTicker(forceUpdateInterval)
-	spanUpdated := make(chan struct{}, 1)
-	nextUpdateAt := time.Now()
+	var lastFrameAt time.Time
+	var nextFrame Ticker // not nil, when frame is scheduled
 
-	go func() {
-		lastUpdatedAt := time.Now()
+	render := func() {
+		if nextFrame != nil {
+			nextFrame.Stop()
+			nextFrame = nil
+		}
 
-		for watching {
-			latestSpanChangeAt := t.latestSpanChangeAt()
-			if latestSpanChangeAt.After(lastUpdatedAt) {
-				lastUpdatedAt = latestSpanChangeAt
-				spanUpdated <- struct{}{}
-			}
+		lastFrameAt = clock.Now()
+		t.update()
+	}
 
-			time.Sleep(time.Millisecond)
+	schedule := func() {
+		if nextFrame != nil {
+			return // change will be rendered in already scheduled frame
 		}
-	}()
 
-	for watching {
-		select {
-		case <-t.watchCtx.Done():
-			watching = false
-			t.update()                         // last update
-			time.Sleep(time.Millisecond * 500) // wait for last term update
-			t.dumpBufferedStdout()             // restore buffered logs to stdout
-			time.Sleep(time.Millisecond * 500) // wait for buffer writing
-			close(t.watchFinished)             // signal that we can finish restoring terminal
-			break
-		case <-ticker.C:
-			t.update() // force update
-			break
-		case <-spanUpdated:
-			if time.Now().Before(nextUpdateAt) {
-				break
-			}
+		wait := frameInterval - clock.Now().Sub(lastFrameAt)
+		if wait <= 0 {
+			render()
+			return
+		}
+
+		nextFrame = clock.NewTicker(wait)
+	}
 
-			nextUpdateAt = time.Now().Add(pauseBetweenUpdates)
-			t.update() // something changed
-			break
+	nextFrameC := func() <-chan time.Time {
+		if nextFrame == nil {
+			return nil // block forever
 		}
+
+		return nextFrame.C()
 	}
-}
 
-func (t *Terminal) latestSpanChangeAt() time.Time {
-	latest := time.Time{}
+	var refresh <-chan time.Time
+	if t.history != nil {
+		ticker := clock.NewTicker(estimateRefreshInterval)
+		defer ticker.Stop()
 
-	for _, span := range t.rootSpans {
-		if span.changedAt.After(latest) {
-			latest = span.changedAt
-		}
+		refresh = ticker.C()
 	}
 
-	return latest
+	resized := notifyResize(t.watchCtx, t.realStdoutSize)
+
+	render() // first frame
+
+	for {
+		select {
+		case <-t.watchCtx.Done():
+			if t.stdoutDrained != nil {
+				<-t.stdoutDrained // wait until all captured stdout is read
+			}
+
+			render() // last update
+			t.printReleaseSummary(SummaryBeforeStdout)
+			t.dumpBufferedStdout() // restore buffered logs to stdout
+			t.printReleaseSummary(SummaryAfterStdout)
+			close(t.watchFinished) // signal that we can finish restoring terminal
+			return
+		case <-resized.changed():
+			render() // redraw with new size right now
+		case <-t.changes.changed():
+			schedule() // something changed
+		case <-nextFrameC():
+			render()
+		case <-refresh:
+			schedule() // estimates is changed with time
+		}
+	}
 }
 
 func (t *Terminal) update() {
-	// clear
-	t.termOs.clear()
-	t.termOs.moveCursor(1, 1)
+	frame := layout{}
 
 	// render main logs
-	if t.active {
+	if t.isActive() {
 		// don`t show normal stdout, because we
 		// dump in normal mode right after release
-		t.termOs.print(renderMainContainer(t.logsContainer) + "\n")
+		t.stdoutMux.Lock()
+		frame = append(frame, renderMainContainer(t.logsContainer)...)
+		t.stdoutMux.Unlock()
 	}
 
 	// render top spans
-	for _, rootSpan := range mostRelevantSpans(t.rootSpans, t.opts.renderOpts.spansMaxRoots) {
-		t.termOs.print(renderSpanWithOptions(rootSpan, t.opts.renderOpts))
+	t.tree.read(func(roots []*Span) {
+		relevantRoots := mostRelevantSpans(roots, t.opts.renderOpts.spansMaxRoots, t.opts.renderOpts.relevance)
+		for _, rootSpan := range relevantRoots {
+			frame = append(frame, renderSpanWithOptions(rootSpan, t.opts.renderOpts)...)
+		}
+
+		if more := renderMoreSpans(relevantRoots, len(roots)-t.tree.hiddenRoots, t.tree.finishedRoots); more != "" {
+			frame.add(priorityFinishedRoot, more)
+		}
+	})
+
+	// clear
+	t.termOs.clear()
+	t.termOs.moveCursor(1, 1)
+
+	// fit frame into terminal, last row is kept empty
+	// for cursor, otherwise terminal will scroll on each frame
+	_, height, _ := t.termOs.frameSize()
+	for _, line := range frame.fit(height - 1) {
+		t.termOs.print(line + "\n")
 	}
 
 	// output to term
 	t.termOs.flush()
 }
 
+func (t *Terminal) realStdoutWidth() int {
+	width, _, err := t.realStdoutSize()
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+
+	return width
+}
+
 func (t *Terminal) dumpBufferedStdout() {
 	// print all captured and hidden messages and logs
 	// back to stdout
-	_, _ = t.realStdout.WriteString("\n")
+	t.stdoutMux.Lock()
+	defer t.stdoutMux.Unlock()
+
+	_, _ = io.WriteString(t.realStdout, "\n")
 	_, _ = t.realStdout.Write(t.stdoutBuffer.Bytes())
-	_, _ = t.realStdout.WriteString("\n")
+	_, _ = io.WriteString(t.realStdout, "\n")
 	t.stdoutBuffer.Reset()
 }
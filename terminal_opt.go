@@ -1,13 +1,49 @@
 package terminal
 
+import "io"
+
 const OptDefaultContainerMaxLines = 4
 const OptDefaultStdoutMaxLines = 8
+const OptDefaultSpanMaxLogs = 20
+const OptDefaultHistoryRegressionThreshold = 0.2
+const OptDefaultMaxFPS = 30
+
+const (
+	SummaryNone         SummaryPosition = iota // summary is not printed
+	SummaryBeforeStdout                        // summary printed before captured stdout
+	SummaryAfterStdout                         // summary printed after captured stdout
+)
 
 type (
+	SummaryPosition int
+
 	terminalOpts = struct {
-		containerMaxLines int
-		stdoutMaxLines    int
-		renderOpts        renderOpts
+		containerMaxLines     int
+		stdoutMaxLines        int
+		spanMaxLogs           int
+		maxFPS                int
+		renderOpts            renderOpts
+		httpStatusAddr        string
+		headless              bool
+		signalHandling        bool
+		withoutSignalHandling bool
+		gracefulInterrupt     bool
+		clock                 Clock
+		output                io.Writer
+		outputSize            terminalSize
+		spanObserver          SpanObserver
+		summaryPosition       SummaryPosition
+		summaryAnalysis       bool
+		summarySlowest        int
+		summaryTimeline       bool
+		timelineMaxDepth      int
+
+		historyName                string
+		historyFile                string
+		historyRegressionThreshold float64
+
+		markdownSummaryWriters []io.Writer
+		markdownSummaryFiles   []string
 	}
 
 	OptsInitializer = func(*terminalOpts)
@@ -30,6 +66,25 @@ func WithStdoutMaxLines(maxLines int) OptsInitializer {
 	}
 }
 
+// WithSpanMaxLogs set how many latest log lines are stored in each span
+// for reports (junit, http status, ..), 0 = unlimited
+// default = OptDefaultSpanMaxLogs
+func WithSpanMaxLogs(maxLogs int) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.spanMaxLogs = maxLogs
+	}
+}
+
+// WithMaxFPS limit how often terminal will be redrawn
+// frames are rendered only when something is changed,
+// many changes between frames are merged into one frame
+// default = OptDefaultMaxFPS
+func WithMaxFPS(fps int) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.maxFPS = fps
+	}
+}
+
 // WithRenderOpts allow to customize spans printing
 func WithRenderOpts(initializers ...RenderOptInitializer) OptsInitializer {
 	return func(opts *terminalOpts) {
@@ -42,3 +97,167 @@ func WithRenderOpts(initializers ...RenderOptInitializer) OptsInitializer {
 		opts.renderOpts = renderOpts
 	}
 }
+
+// WithHTTPStatus will serve current span tree while spans is recorded
+// (captured output, non terminal output or headless mode) on given addr (for example "localhost:8080"):
+//   - "/"           - html page with live updates
+//   - "/spans.json" - current span tree in json
+//   - "/events"     - span tree stream (server-sent events)
+func WithHTTPStatus(addr string) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.httpStatusAddr = addr
+	}
+}
+
+// WithMarkdownSummary will write markdown table with all spans
+// to w, when output is released
+func WithMarkdownSummary(w io.Writer) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.markdownSummaryWriters = append(opt.markdownSummaryWriters, w)
+	}
+}
+
+// WithMarkdownSummaryFile will append markdown table with all spans
+// to file (created if not exist), when output is released
+// useful with github actions: WithMarkdownSummaryFile(os.Getenv("GITHUB_STEP_SUMMARY"))
+// empty path is ignored
+func WithMarkdownSummaryFile(path string) OptsInitializer {
+	return func(opt *terminalOpts) {
+		if path == "" {
+			return
+		}
+
+		opt.markdownSummaryFiles = append(opt.markdownSummaryFiles, path)
+	}
+}
+
+// WithReleaseSummary will print full span tree with durations,
+// errors and totals, when output is released
+// position is relative to captured stdout dump
+// summary is printed also when output is not captured (CI, pipes, headless)
+// default = SummaryNone
+func WithReleaseSummary(position SummaryPosition) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.summaryPosition = position
+	}
+}
+
+// WithReleaseSummaryAnalysis will add critical path and top N slowest spans
+// (by self time) to summary printed on release, see WithReleaseSummary
+// slowestCount = 0 will print only critical path
+func WithReleaseSummaryAnalysis(slowestCount int) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.summaryAnalysis = true
+		opt.summarySlowest = slowestCount
+	}
+}
+
+// WithReleaseSummaryTimeline will add timeline of the run to summary
+// printed on release, see WithReleaseSummary
+// maxDepth limit displayed spans: 0 = only root spans, 1 = root and child, etc..
+func WithReleaseSummaryTimeline(maxDepth int) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.summaryTimeline = true
+		opt.timelineMaxDepth = maxDepth
+	}
+}
+
+// WithHistory will store durations of all finished spans between runs
+// in user cache dir (for example ~/.cache/span-terminal/{name}.json)
+// finished spans will display change relative to median of previous runs
+// and regressions will be flagged in release summary
+func WithHistory(name string) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.historyName = name
+	}
+}
+
+// WithHistoryFile is same as WithHistory, but with custom file path
+func WithHistoryFile(path string) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.historyFile = path
+	}
+}
+
+// WithHistoryRegressionThreshold set relative change to median
+// when span is flagged as regression (0.2 = 20% slower)
+// default = OptDefaultHistoryRegressionThreshold
+func WithHistoryRegressionThreshold(threshold float64) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.historyRegressionThreshold = threshold
+	}
+}
+
+// WithHeadless will record all spans without capturing
+// stdout and rendering anything, spans can be started
+// without CaptureOutput call (useful for tests)
+func WithHeadless() OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.headless = true
+	}
+}
+
+// WithSignalHandling will release terminal on SIGINT/SIGTERM, while
+// output is captured, and re-raise signal, so process is terminated
+// with restored stdout. Signal is re-raised to whole process, so
+// application with own signal.Notify will receive it second time,
+// such applications should not use this option, and release terminal
+// (Release / Guard) in own shutdown instead
+// default = disabled
+func WithSignalHandling() OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.signalHandling = true
+	}
+}
+
+// WithoutSignalHandling will disable all signal handling, even
+// enabled with WithSignalHandling or WithGracefulInterrupt
+func WithoutSignalHandling() OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.withoutSignalHandling = true
+	}
+}
+
+// WithGracefulInterrupt will make interrupt (Ctrl+C) two-stage:
+// first one cancel terminal Context and mark all running spans
+// as cancelling, second one will release terminal and exit
+// signals is handled from terminal creation, even without capture
+// (second interrupt is re-raised, like with WithSignalHandling)
+func WithGracefulInterrupt() OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.gracefulInterrupt = true
+	}
+}
+
+// WithSpanObserver will send all span changes to observer
+func WithSpanObserver(observer SpanObserver) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.spanObserver = observer
+	}
+}
+
+// WithOutput will render terminal to custom output (instead of stdout)
+// with given size in chars, output is always rendered
+// as ANSI terminal, useful with virtual screens in tests
+func WithOutput(w io.Writer, size func() (width, height int)) OptsInitializer {
+	return func(opt *terminalOpts) {
+		opt.output = w
+		opt.outputSize = func() (int, int, error) {
+			width, height := size()
+			return width, height, nil
+		}
+	}
+}
+
+// WithClock set source of time for span timestamps
+// and render scheduling, useful for deterministic tests
+// default = system clock, nil clock is ignored
+func WithClock(clock Clock) OptsInitializer {
+	return func(opt *terminalOpts) {
+		if clock == nil {
+			return
+		}
+
+		opt.clock = clock
+	}
+}
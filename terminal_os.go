@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -13,17 +14,37 @@ import (
 // clear screen
 const osClear = "\033[2J"
 
-type termOS struct {
-	terminal *os.File
-	writer   *bufio.Writer
-	screen   *bytes.Buffer
-}
+type (
+	termOS struct {
+		size   terminalSize
+		writer *bufio.Writer
+		screen *bytes.Buffer
+
+		// size of current frame, fetched once per frame
+		width, height int
+		sizeKnown     bool
+	}
+
+	// terminalSize return current terminal size in chars
+	terminalSize = func() (width, height int, err error)
+)
 
-func newTermOs(terminal *os.File) *termOS {
+func newTermOs(terminal io.Writer, size terminalSize) *termOS {
 	return &termOS{
-		terminal: terminal,
-		writer:   bufio.NewWriter(terminal),
-		screen:   new(bytes.Buffer),
+		size:   size,
+		writer: bufio.NewWriter(terminal),
+		screen: new(bytes.Buffer),
+	}
+}
+
+func fileTerminalSize(file *os.File) terminalSize {
+	return func() (int, int, error) {
+		size, err := tsize.FgetSize(file)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return size.Width, size.Height, nil
 	}
 }
 
@@ -40,19 +61,47 @@ func (t *termOS) print(src string) {
 }
 
 func (t *termOS) flush() {
-	size, err := tsize.FgetSize(t.terminal)
+	width, height, err := t.frameSize()
 	if err != nil {
+		t.screen.Reset()
 		return
 	}
 
+	// every line is truncated to terminal width, so
+	// one line of frame is always one line on screen
 	for idx, str := range strings.SplitAfter(t.screen.String(), "\n") {
-		if idx > size.Height {
-			return
+		if idx > height {
+			break
 		}
 
-		_, _ = t.writer.WriteString(str)
+		line := strings.TrimSuffix(str, "\n")
+		_, _ = t.writer.WriteString(truncateLine(line, width))
+
+		if len(line) != len(str) {
+			_, _ = t.writer.WriteString("\n")
+		}
 	}
 
 	_ = t.writer.Flush()
 	t.screen.Reset()
+
+	// size can be changed without any signal (custom output,
+	// windows, ..), so next frame will fetch it again
+	t.sizeKnown = false
+}
+
+// frameSize return terminal size of current frame, size is
+// fetched on first call, and kept until frame is flushed
+func (t *termOS) frameSize() (int, int, error) {
+	if t.sizeKnown {
+		return t.width, t.height, nil
+	}
+
+	width, height, err := t.size()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	t.width, t.height, t.sizeKnown = width, height, true
+	return width, height, nil
 }
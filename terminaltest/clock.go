@@ -0,0 +1,98 @@
+package terminaltest
+
+import (
+	"sync"
+	"time"
+
+	terminal "github.com/fe3dback/span-terminal"
+)
+
+type (
+	// ManualClock is terminal.Clock, that is changed only manually
+	// with Advance or Set, tickers fire when clock is advanced past theirs interval
+	ManualClock struct {
+		now     time.Time
+		tickers []*manualTicker
+
+		mux sync.Mutex
+	}
+
+	manualTicker struct {
+		clock    *ManualClock
+		c        chan time.Time
+		interval time.Duration
+		next     time.Time
+		stopped  bool
+	}
+)
+
+// NewManualClock create clock started at given time
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) NewTicker(d time.Duration) terminal.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for ManualClock.NewTicker")
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	ticker := &manualTicker{
+		clock:    c,
+		c:        make(chan time.Time, 1),
+		interval: d,
+		next:     c.now.Add(d),
+	}
+
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance move clock forward, and fire all tickers
+// slow receivers will lose ticks, like with time.Ticker
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set move clock to given time
+func (c *ManualClock) Set(now time.Time) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.now = now
+
+	for _, ticker := range c.tickers {
+		if ticker.stopped {
+			continue
+		}
+
+		for !ticker.next.After(now) {
+			select {
+			case ticker.c <- ticker.next:
+			default:
+			}
+
+			ticker.next = ticker.next.Add(ticker.interval)
+		}
+	}
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *manualTicker) Stop() {
+	t.clock.mux.Lock()
+	defer t.clock.mux.Unlock()
+
+	t.stopped = true
+}
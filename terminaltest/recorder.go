@@ -0,0 +1,250 @@
+// Package terminaltest provide tools for testing code instrumented with spans
+package terminaltest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	terminal "github.com/fe3dback/span-terminal"
+)
+
+type (
+	// Recorder capture all spans, logs, progress values and statuses
+	// in memory, without touching stdout
+	Recorder struct {
+		terminal *terminal.Terminal
+		roots    []*RecordedSpan
+		spans    map[int64]*RecordedSpan
+		events   []terminal.SpanEvent
+
+		mux sync.RWMutex
+	}
+
+	// RecordedSpan is everything that happened with span
+	RecordedSpan struct {
+		ID       int64
+		Title    string
+		Parent   *RecordedSpan
+		Children []*RecordedSpan
+		Logs     []string // all logs written directly to span, without child logs
+		Progress []int    // all progress updates in %, in order
+		Err      error    // latest span error
+		Finished bool
+		StartAt  time.Time
+		EndAt    time.Time
+	}
+)
+
+// New create recorder with headless terminal
+// additional terminal options can be passed
+func New(opts ...terminal.OptsInitializer) *Recorder {
+	r := &Recorder{
+		spans: make(map[int64]*RecordedSpan),
+	}
+
+	r.terminal = terminal.NewTerminal(append([]terminal.OptsInitializer{
+		terminal.WithHeadless(),
+		terminal.WithSpanObserver(r.observe),
+	}, opts...)...)
+
+	return r
+}
+
+// Context bind recorder to context, all spans started
+// with terminal.StartSpan from this context will be recorded
+func (r *Recorder) Context(ctx context.Context) context.Context {
+	return terminal.ContextWithTerminal(ctx, r.terminal)
+}
+
+// Terminal return recording terminal
+func (r *Recorder) Terminal() *terminal.Terminal {
+	return r.terminal
+}
+
+// Roots return all recorded root spans in order of creation
+func (r *Recorder) Roots() []*RecordedSpan {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return append([]*RecordedSpan(nil), r.roots...)
+}
+
+// Events return all recorded span events in order of happening
+func (r *Recorder) Events() []terminal.SpanEvent {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return append([]terminal.SpanEvent(nil), r.events...)
+}
+
+// Find return span by titles of all its ancestors and span itself
+// nil when span not exist
+func (r *Recorder) Find(path ...string) *RecordedSpan {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	return findSpan(r.roots, path)
+}
+
+// Tree return titles of all spans, one per line
+// child spans indented with two spaces per level
+func (r *Recorder) Tree() string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	out := strings.Builder{}
+
+	var walk func(spans []*RecordedSpan, depth int)
+	walk = func(spans []*RecordedSpan, depth int) {
+		for _, span := range spans {
+			out.WriteString(strings.Repeat("  ", depth) + span.Title + "\n")
+			walk(span.Children, depth+1)
+		}
+	}
+	walk(r.roots, 0)
+
+	return out.String()
+}
+
+// AssertTree check that recorded span tree is equal to expected
+// expected format is same as Tree, leading and trailing newlines are ignored
+func (r *Recorder) AssertTree(t testing.TB, expected string) {
+	t.Helper()
+
+	got := strings.Trim(r.Tree(), "\n")
+	expected = strings.Trim(expected, "\n")
+
+	if got != expected {
+		t.Errorf("span tree is not equal\nexpected:\n%s\n\ngot:\n%s", expected, got)
+	}
+}
+
+// AssertSpan check that span exist and return it
+// test is failed immediately, when span not exist
+func (r *Recorder) AssertSpan(t testing.TB, path ...string) *RecordedSpan {
+	t.Helper()
+
+	span := r.Find(path...)
+	if span == nil {
+		t.Fatalf("span '%s' not recorded, got:\n%s", strings.Join(path, " > "), r.Tree())
+	}
+
+	return span
+}
+
+// AssertAllEnded check that End is called for all recorded spans
+func (r *Recorder) AssertAllEnded(t testing.TB) {
+	t.Helper()
+
+	r.walk(func(path []string, span *RecordedSpan) {
+		if !span.Finished {
+			t.Errorf("span '%s' is not ended", strings.Join(path, " > "))
+		}
+	})
+}
+
+// AssertNoErrors check that all recorded spans is not failed
+func (r *Recorder) AssertNoErrors(t testing.TB) {
+	t.Helper()
+
+	r.walk(func(path []string, span *RecordedSpan) {
+		if span.Err != nil {
+			t.Errorf("span '%s' is failed: %v", strings.Join(path, " > "), span.Err)
+		}
+	})
+}
+
+// Duration is time between span start and end
+// zero for not finished spans
+func (s *RecordedSpan) Duration() time.Duration {
+	if !s.Finished {
+		return 0
+	}
+
+	return s.EndAt.Sub(s.StartAt)
+}
+
+func (r *Recorder) observe(event terminal.SpanEvent) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.events = append(r.events, event)
+
+	if event.Kind == terminal.SpanEventStart {
+		span := &RecordedSpan{
+			ID:      event.SpanID,
+			Title:   event.Title,
+			Parent:  r.spans[event.ParentID],
+			StartAt: event.At,
+		}
+
+		r.spans[event.SpanID] = span
+
+		if span.Parent == nil {
+			r.roots = append(r.roots, span)
+		} else {
+			span.Parent.Children = append(span.Parent.Children, span)
+		}
+
+		return
+	}
+
+	span, exist := r.spans[event.SpanID]
+	if !exist {
+		return
+	}
+
+	switch event.Kind {
+	case terminal.SpanEventWrite:
+		span.Logs = append(span.Logs, event.Message)
+	case terminal.SpanEventProgress:
+		span.Progress = append(span.Progress, event.Progress)
+	case terminal.SpanEventError:
+		span.Err = event.Err
+	case terminal.SpanEventEnd:
+		span.Finished = true
+		span.EndAt = event.At
+	}
+}
+
+func (r *Recorder) walk(fn func(path []string, span *RecordedSpan)) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	var walk func(parent []string, spans []*RecordedSpan)
+	walk = func(parent []string, spans []*RecordedSpan) {
+		for _, span := range spans {
+			path := append(append(make([]string, 0, len(parent)+1), parent...), span.Title)
+
+			fn(path, span)
+			walk(path, span.Children)
+		}
+	}
+
+	walk(nil, r.roots)
+}
+
+func findSpan(spans []*RecordedSpan, path []string) *RecordedSpan {
+	if len(path) == 0 {
+		return nil
+	}
+
+	for _, span := range spans {
+		if span.Title != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			return span
+		}
+
+		if found := findSpan(span.Children, path[1:]); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
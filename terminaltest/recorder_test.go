@@ -0,0 +1,119 @@
+package terminaltest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	terminal "github.com/fe3dback/span-terminal"
+	"github.com/stretchr/testify/assert"
+)
+
+func build(ctx context.Context) {
+	ctx, span := terminal.StartSpan(ctx, "build")
+	defer span.End()
+
+	span.Write("building..")
+
+	wg := sync.WaitGroup{}
+	for _, pkg := range []string{"api", "db"} {
+		wg.Add(1)
+
+		go func(pkg string) {
+			defer wg.Done()
+
+			_, pkgSpan := terminal.StartSpan(ctx, "compile "+pkg)
+			defer pkgSpan.End()
+
+			pkgSpan.UpdateProgress(0.5)
+			pkgSpan.UpdateProgress(1)
+
+			if pkg == "db" {
+				pkgSpan.SetError(errors.New("syntax error"))
+			}
+		}(pkg)
+	}
+
+	wg.Wait()
+}
+
+func TestRecorder(t *testing.T) {
+	rec := New()
+	build(rec.Context(context.Background()))
+
+	assert.Len(t, rec.Roots(), 1)
+	rec.AssertAllEnded(t)
+
+	root := rec.AssertSpan(t, "build")
+	assert.Equal(t, []string{"building.."}, root.Logs)
+	assert.Len(t, root.Children, 2)
+
+	db := rec.AssertSpan(t, "build", "compile db")
+	assert.Equal(t, []int{50, 100}, db.Progress)
+	assert.EqualError(t, db.Err, "syntax error")
+	assert.True(t, db.Finished)
+
+	api := rec.AssertSpan(t, "build", "compile api")
+	assert.NoError(t, api.Err)
+	assert.Nil(t, rec.Find("build", "compile web"))
+}
+
+func TestRecorder_AssertTree(t *testing.T) {
+	rec := New()
+	ctx := rec.Context(context.Background())
+
+	ctx, root := terminal.StartSpan(ctx, "deploy")
+	_, upload := terminal.StartSpan(ctx, "upload")
+	upload.End()
+	root.End()
+
+	rec.AssertTree(t, `
+deploy
+  upload
+`)
+	rec.AssertNoErrors(t)
+}
+
+func TestRecorder_logs(t *testing.T) {
+	rec := New(terminal.WithSpanMaxLogs(1))
+	ctx := rec.Context(context.Background())
+
+	ctx, root := terminal.StartSpan(ctx, "build")
+	_, child := terminal.StartSpan(ctx, "compile")
+
+	root.Write("root 1")
+	child.Write("child 1")
+	child.Write("child 2")
+	root.Write("root 2")
+
+	child.End()
+	root.End()
+
+	// child logs is displayed in parent container, but recorded only in child
+	assert.Equal(t, []string{"root 1", "root 2"}, rec.AssertSpan(t, "build").Logs)
+	assert.Equal(t, []string{"child 1", "child 2"}, rec.AssertSpan(t, "build", "compile").Logs)
+}
+
+func TestRecorder_withManualClock(t *testing.T) {
+	clock := NewManualClock(time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC))
+	rec := New(terminal.WithClock(clock))
+	ctx := rec.Context(context.Background())
+
+	ctx, root := terminal.StartSpan(ctx, "build")
+	clock.Advance(time.Second)
+
+	_, compile := terminal.StartSpan(ctx, "compile")
+	clock.Advance(time.Second * 2)
+	compile.End()
+
+	clock.Advance(time.Millisecond * 500)
+	root.End()
+
+	assert.Equal(t, time.Millisecond*3500, rec.AssertSpan(t, "build").Duration())
+	assert.Equal(t, time.Second*2, rec.AssertSpan(t, "build", "compile").Duration())
+
+	snapshot := rec.Terminal().Snapshot()
+	assert.Equal(t, time.Millisecond*3500, snapshot[0].Duration)
+}
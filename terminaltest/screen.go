@@ -0,0 +1,15 @@
+package terminaltest
+
+import "github.com/fe3dback/span-terminal/internal/vt"
+
+// Screen is virtual terminal, that interpret ANSI escape
+// sequences into grid of chars, can be used as terminal output
+//
+//	screen := terminaltest.NewScreen(80, 24)
+//	term := terminal.NewTerminal(terminal.WithOutput(screen, screen.Size))
+type Screen = vt.Screen
+
+// NewScreen create virtual terminal with fixed size in chars
+func NewScreen(width, height int) *Screen {
+	return vt.NewScreen(width, height)
+}
@@ -0,0 +1,94 @@
+package terminal
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/truncate"
+)
+
+// tail of truncated line
+const truncateTail = "…"
+
+// delimiter between start and end of line, truncated in the middle
+const truncateMiddleDelimiter = " .. "
+
+// displayWidth is count of terminal cells used by string
+// ansi escape codes are not printed, wide runes (CJK, emoji) use two cells
+func displayWidth(s string) int {
+	return ansi.PrintableRuneWidth(s)
+}
+
+// truncateLine will cut line end to fit into width cells
+// ansi escape codes are kept, and style is reset after cut
+func truncateLine(line string, width int) string {
+	if width <= 0 || displayWidth(line) <= width {
+		return line
+	}
+
+	// only padding is not fit, it can be cut without tail
+	if displayWidth(strings.TrimRight(stripANSI(line), " ")) <= width {
+		return truncate.String(line, uint(width))
+	}
+
+	return truncate.StringWithTail(line, uint(width), truncateTail)
+}
+
+// truncateMiddle will cut line middle to fit into width cells,
+// so start and end of line are visible. Line should not contain ansi codes
+func truncateMiddle(line string, width int) string {
+	if width <= 0 || displayWidth(line) <= width {
+		return line
+	}
+
+	contentWidth := width - len(truncateMiddleDelimiter)
+	if contentWidth < 2 {
+		return truncateLine(line, width)
+	}
+
+	left := truncate.String(line, uint(contentWidth/2))
+	right := lastCells(line, contentWidth-displayWidth(left))
+
+	return left + truncateMiddleDelimiter + right
+}
+
+// lastCells return end of string, that fit into width cells
+func lastCells(s string, width int) string {
+	runes := []rune(s)
+	from, used := len(runes), 0
+
+	for from > 0 {
+		runeWidth := runewidth.RuneWidth(runes[from-1])
+		if used+runeWidth > width {
+			break
+		}
+
+		used += runeWidth
+		from--
+	}
+
+	return string(runes[from:])
+}
+
+// stripANSI remove all ansi escape codes from string
+func stripANSI(s string) string {
+	out := strings.Builder{}
+	inSequence := false
+
+	for _, c := range s {
+		if c == ansi.Marker {
+			inSequence = true
+			continue
+		}
+
+		if inSequence {
+			inSequence = !ansi.IsTerminator(c)
+			continue
+		}
+
+		out.WriteRune(c)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,47 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_truncateLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  string
+	}{
+		{name: "fit", line: "hello", width: 5, want: "hello"},
+		{name: "ascii", line: "hello world", width: 8, want: "hello w…"},
+		{name: "cjk", line: "日本語のテキスト", width: 7, want: "日本語…"},
+		{name: "emoji", line: "🚀🚀🚀🚀", width: 6, want: "🚀🚀…"},
+		{name: "ansi not counted", line: "\x1b[1mbold\x1b[0m", width: 4, want: "\x1b[1mbold\x1b[0m"},
+		{name: "ansi reset after cut", line: "\x1b[1mbold text\x1b[0m", width: 5, want: "\x1b[1mbold\x1b[0m…"},
+		{name: "padding cut without tail", line: "\x1b[35mshort      \x1b[0m", width: 6, want: "\x1b[35mshort \x1b[0m"},
+		{name: "unknown width", line: "hello", width: 0, want: "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateLine(tt.line, tt.width)
+
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, displayWidth(got), maxInt(tt.width, displayWidth(tt.line)))
+		})
+	}
+}
+
+func Test_truncateMiddle(t *testing.T) {
+	assert.Equal(t, "short", truncateMiddle("short", 10))
+	assert.Equal(t, "abc .. xyz", truncateMiddle("abcdefghijklmnopqrstuvwxyz", 10))
+	assert.Equal(t, "日 .. 本語", truncateMiddle("日本語のテキスト日本語", 10))
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
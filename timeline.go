@@ -0,0 +1,163 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	timelineMinBarWidth   = 10
+	timelineMinLabelWidth = 4
+	timelineMaxLabelWidth = 32
+	timelineDurationWidth = 6
+	timelineDelimiters    = 6 // two " | " between label, bar and duration
+
+	timelineBarDone    = "█"
+	timelineBarRunning = "▒"
+	timelineBarFailed  = "▓"
+	timelineBarEmpty   = "·"
+)
+
+// WriteTimeline will write gantt-like timeline of all spans
+// one row per span, bar is positioned relative to run start
+// and scaled to fit into width (in chars)
+// maxDepth limit displayed spans: 0 = only root spans, 1 = root and child, etc..
+func (t *Terminal) WriteTimeline(w io.Writer, width int, maxDepth int) error {
+	_, err := io.WriteString(w, renderTimeline(t.Snapshot(), width, maxDepth))
+	return err
+}
+
+func renderTimeline(spans []*SpanSnapshot, width int, maxDepth int) string {
+	spans = visibleSnapshots(spans)
+	rows := make([]*SpanSnapshot, 0)
+	labels := make([]string, 0)
+	labelWidth := 0
+
+	walkSnapshots(spans, func(_ []string, span *SpanSnapshot) {
+		if span.Depth > maxDepth {
+			return
+		}
+
+		label := strings.Repeat(" ", span.Depth) + span.Title
+		if displayWidth(label) > labelWidth {
+			labelWidth = displayWidth(label)
+		}
+
+		rows = append(rows, span)
+		labels = append(labels, label)
+	})
+
+	if len(rows) == 0 {
+		return ""
+	}
+
+	if labelWidth > timelineMaxLabelWidth {
+		labelWidth = timelineMaxLabelWidth
+	}
+
+	if labelWidth > width/3 {
+		labelWidth = width / 3
+	}
+
+	// label | bar | duration
+	fixedWidth := timelineDurationWidth + timelineDelimiters
+	barWidth := width - labelWidth - fixedWidth
+	if barWidth < timelineMinBarWidth {
+		// narrow output, label is shortened to keep bar readable
+		labelWidth = width - fixedWidth - timelineMinBarWidth
+		if labelWidth < timelineMinLabelWidth {
+			labelWidth = timelineMinLabelWidth
+		}
+
+		barWidth = width - labelWidth - fixedWidth
+		if barWidth < 1 {
+			barWidth = 1
+		}
+	}
+
+	runStart, runEnd := snapshotsTimeRange(spans)
+	total := runEnd.Sub(runStart)
+
+	column := func(at time.Time) int {
+		if total <= 0 {
+			return 0
+		}
+
+		return int(float64(at.Sub(runStart)) / float64(total) * float64(barWidth))
+	}
+
+	out := strings.Builder{}
+
+	for idx, span := range rows {
+		from := column(span.StartAt)
+		to := column(snapshotEnd(span))
+
+		if from >= barWidth {
+			from = barWidth - 1
+		}
+		if to <= from {
+			// span is too short for this scale, but it still should be visible
+			to = from + 1
+		}
+
+		bar := strings.Repeat(timelineBarEmpty, from) +
+			timelineStyle(span).Render(strings.Repeat(timelineBar(span), to-from)) +
+			strings.Repeat(timelineBarEmpty, barWidth-to)
+
+		out.WriteString(fmt.Sprintf("%s | %s | %*s\n",
+			timelineLabel(labels[idx], labelWidth),
+			bar,
+			timelineDurationWidth,
+			renderSnapshotDuration(span),
+		))
+	}
+
+	// time axis
+	axisEnd := renderTook(total)
+	out.WriteString(fmt.Sprintf("%s | 0%*s |\n",
+		strings.Repeat(" ", labelWidth),
+		barWidth-1,
+		axisEnd,
+	))
+
+	return out.String()
+}
+
+// timelineLabel cut or pad label to exactly width cells
+func timelineLabel(label string, width int) string {
+	label = truncateLine(label, width)
+
+	if padding := width - displayWidth(label); padding > 0 {
+		label += strings.Repeat(" ", padding)
+	}
+
+	return label
+}
+
+func timelineBar(span *SpanSnapshot) string {
+	if span.Error != "" {
+		return timelineBarFailed
+	}
+
+	if !span.Finished {
+		return timelineBarRunning
+	}
+
+	return timelineBarDone
+}
+
+func timelineStyle(span *SpanSnapshot) lipgloss.Style {
+	if span.Error != "" {
+		return styleStatusFailed
+	}
+
+	if !span.Finished {
+		return styleStatusActive
+	}
+
+	return styleStatusDone
+}
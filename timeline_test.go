@@ -0,0 +1,59 @@
+package terminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal_WriteTimeline_golden(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		maxDepth int
+	}{
+		{name: "timeline_roots", width: 60, maxDepth: 0},
+		{name: "timeline_tree", width: 60, maxDepth: 2},
+		{name: "timeline_narrow", width: 20, maxDepth: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			require.NoError(t, newExportTestTerminal().WriteTimeline(buf, tt.width, tt.maxDepth))
+
+			for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+				assert.LessOrEqual(t, displayWidth(line), tt.width, "row should fit into width: %q", line)
+			}
+
+			assertGolden(t, tt.name, stripANSI(buf.String()))
+		})
+	}
+}
+
+func Test_timelineLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		width int
+		want  string
+	}{
+		{label: "build", width: 8, want: "build   "},
+		{label: "build", width: 5, want: "build"},
+		{label: "compile", width: 5, want: "comp…"},
+		{label: "ビルド", width: 6, want: "ビルド"},
+		{label: "ビルド", width: 5, want: "ビル…"},
+		{label: "ビルド", width: 4, want: "ビ… "},
+		{label: "build", width: 1, want: "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			assert.Equal(t, tt.want, timelineLabel(tt.label, tt.width))
+		})
+	}
+}
+
+func Test_renderTimeline_empty(t *testing.T) {
+	assert.Empty(t, renderTimeline(nil, 60, 1))
+}
@@ -0,0 +1,111 @@
+package terminal
+
+import "sync"
+
+// spanTree own all spans of one terminal
+// single lock guard whole tree: state of all spans, parent-child
+// relations and roots. All span mutations are done under write lock,
+// renderers and exporters read tree under read lock
+type spanTree struct {
+	roots         []*Span
+	finishedRoots int             // count of finished root spans (not hidden)
+	hiddenRoots   int             // count of hidden root spans
+	lastID        spanID          // span id generator, unique inside tree
+	changes       *changeNotifier // notified on every change of any span in tree
+
+	cancelling bool // terminal context is canceled, running spans are winding down
+
+	mux sync.RWMutex
+}
+
+func newSpanTree(changes *changeNotifier) *spanTree {
+	return &spanTree{
+		roots:   make([]*Span, 0),
+		changes: changes,
+	}
+}
+
+// read will call fn with all root spans under read lock
+// fn should not keep references to roots slice
+func (tr *spanTree) read(fn func(roots []*Span)) {
+	tr.mux.RLock()
+	defer tr.mux.RUnlock()
+
+	fn(tr.roots)
+}
+
+// should be called under write lock
+func (tr *spanTree) nextID() spanID {
+	tr.lastID++
+	return tr.lastID
+}
+
+// should be called under write lock
+func (tr *spanTree) touch() {
+	tr.changes.notify()
+}
+
+// cancel will mark all running spans as cancelling
+func (tr *spanTree) cancel() {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+
+	tr.cancelling = true
+	tr.touch()
+}
+
+func (tr *spanTree) isCancelling() bool {
+	tr.mux.RLock()
+	defer tr.mux.RUnlock()
+
+	return tr.cancelling
+}
+
+// countFinished will track count of finished child in parent
+// should be called under write lock, once per span
+func (tr *spanTree) countFinished(span *Span) {
+	if span.hidden {
+		return
+	}
+
+	if span.parent != nil {
+		span.parent.finishedChild++
+		return
+	}
+
+	tr.finishedRoots++
+}
+
+// countHidden will track count of hidden child in parent
+// should be called under write lock, once per span
+func (tr *spanTree) countHidden(span *Span) {
+	if !span.hidden {
+		return
+	}
+
+	if span.parent != nil {
+		span.parent.hiddenChild++
+		return
+	}
+
+	tr.hiddenRoots++
+}
+
+// countPinned will track count of pinned descendants in all ancestors,
+// so pinned span is displayed even when its parent is over render limits
+// should be called under write lock, once per span
+func (tr *spanTree) countPinned(span *Span) {
+	if !span.pinned || span.hidden {
+		return
+	}
+
+	for parent := span.parent; parent != nil; parent = parent.parent {
+		if parent.hidden {
+			return // pinned span is never rendered under hidden parent
+		}
+	}
+
+	for parent := span.parent; parent != nil; parent = parent.parent {
+		parent.pinnedChild++
+	}
+}
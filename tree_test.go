@@ -0,0 +1,129 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/fe3dback/span-terminal/internal/vt"
+	"github.com/stretchr/testify/assert"
+)
+
+// should be run with -race
+func TestTerminal_concurrentSpans(t *testing.T) {
+	const workers = 16
+	const spansPerWorker = 50
+
+	screen := vt.NewScreen(120, 40)
+	term := NewTerminal(WithHeadless(), WithOutput(screen, screen.Size))
+
+	ctx, root := term.StartSpan(context.Background(), "root")
+
+	rendering := make(chan struct{})
+	renderDone := make(chan struct{})
+	go func() {
+		defer close(renderDone)
+
+		for {
+			select {
+			case <-rendering:
+				return
+			default:
+				term.update()
+				_ = term.Snapshot()
+				_ = term.statusJSON()
+			}
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			ctx, worker := term.StartSpan(ctx, fmt.Sprintf("worker %d", w))
+			defer worker.End()
+
+			for i := 0; i < spansPerWorker; i++ {
+				_, span := term.StartSpan(ctx, fmt.Sprintf("task %d", i), WithAttribute("worker", fmt.Sprint(w)))
+				span.Write("working")
+				span.UpdateProgress(0.5)
+
+				if i%10 == 0 {
+					span.SetError(errors.New("failed"))
+				}
+
+				// parent is ended concurrently with own child sometimes
+				if i == spansPerWorker-1 {
+					go worker.End()
+				}
+
+				span.End()
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	root.End()
+
+	close(rendering)
+	<-renderDone
+
+	snapshot := term.Snapshot()
+	assert.Len(t, snapshot, 1)
+	assert.Len(t, snapshot[0].Children, workers)
+
+	total := 0
+	walkSnapshots(snapshot, func(_ []string, span *SpanSnapshot) {
+		total++
+		assert.True(t, span.Finished)
+	})
+	assert.Equal(t, 1+workers+workers*spansPerWorker, total)
+}
+
+// should be run with -race
+func TestTerminal_span_parentFromOtherTerminal(t *testing.T) {
+	first := NewTerminal(WithHeadless())
+	second := NewTerminal(WithHeadless())
+
+	ctx, firstRoot := first.StartSpan(context.Background(), "first root")
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			_, span := first.StartSpan(ctx, fmt.Sprintf("first child %d", i))
+			span.End()
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			_, span := second.StartSpan(ctx, fmt.Sprintf("second %d", i))
+			span.End()
+		}
+	}()
+
+	wg.Wait()
+	firstRoot.End()
+
+	firstSnapshot := first.Snapshot()
+	assert.Len(t, firstSnapshot, 1)
+	assert.Len(t, firstSnapshot[0].Children, 100)
+
+	// spans started from context of other terminal are roots
+	secondSnapshot := second.Snapshot()
+	assert.Len(t, secondSnapshot, 100)
+	for _, span := range secondSnapshot {
+		assert.Equal(t, 0, span.Depth)
+	}
+}
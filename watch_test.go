@@ -0,0 +1,311 @@
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// frameCounter count flushes of terminal frames,
+// big frame can be flushed with few writes, but only first one start with clear
+type frameCounter struct {
+	frames   int64
+	rendered chan struct{} // optional, receive every frame
+}
+
+func newFrameCounter() *frameCounter {
+	return &frameCounter{rendered: make(chan struct{}, 1024)}
+}
+
+func (c *frameCounter) Write(p []byte) (int, error) {
+	if !bytes.HasPrefix(p, []byte(osClear)) {
+		return len(p), nil
+	}
+
+	atomic.AddInt64(&c.frames, 1)
+
+	select {
+	case c.rendered <- struct{}{}:
+	default:
+	}
+
+	return len(p), nil
+}
+
+func (c *frameCounter) count() int64 {
+	return atomic.LoadInt64(&c.frames)
+}
+
+// waitFrame block until next frame is rendered
+func (c *frameCounter) waitFrame(t testing.TB) {
+	t.Helper()
+
+	select {
+	case <-c.rendered:
+	case <-time.After(time.Second * 5):
+		t.Fatal("frame is not rendered")
+	}
+}
+
+// testClock is Clock changed only by advance, tickers fire
+// when clock is advanced past theirs interval (like terminaltest.ManualClock,
+// that can't be used here, because it imports this package)
+type testClock struct {
+	now     time.Time
+	tickers []*testTicker
+	created chan struct{} // receive every created ticker
+
+	mux sync.Mutex
+}
+
+type testTicker struct {
+	clock    *testClock
+	c        chan time.Time
+	interval time.Duration
+	next     time.Time
+	stopped  bool
+}
+
+func newTestClock() *testClock {
+	return &testClock{
+		now:     time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+		created: make(chan struct{}, 1024),
+	}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) NewTicker(d time.Duration) Ticker {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	ticker := &testTicker{clock: c, c: make(chan time.Time, 1), interval: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, ticker)
+
+	select {
+	case c.created <- struct{}{}:
+	default:
+	}
+
+	return ticker
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.now = c.now.Add(d)
+
+	for _, ticker := range c.tickers {
+		for !ticker.stopped && !ticker.next.After(c.now) {
+			select {
+			case ticker.c <- ticker.next:
+			default:
+			}
+
+			ticker.next = ticker.next.Add(ticker.interval)
+		}
+	}
+}
+
+// waitTicker block until next ticker is created (frame is scheduled)
+func (c *testClock) waitTicker(t testing.TB) {
+	t.Helper()
+
+	select {
+	case <-c.created:
+	case <-time.After(time.Second * 5):
+		t.Fatal("ticker is not created")
+	}
+}
+
+func (t *testTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *testTicker) Stop() {
+	t.clock.mux.Lock()
+	defer t.clock.mux.Unlock()
+
+	t.stopped = true
+}
+
+func startTestWatch(term *Terminal) (stop func()) {
+	term.watchCtx, term.watchCancel = context.WithCancel(context.Background())
+	term.watchFinished = make(chan struct{})
+	term.setActive(true)
+	term.setRecording(true)
+
+	go term.watch()
+
+	return func() {
+		term.setActive(false)
+		term.watchCancel()
+		<-term.watchFinished
+	}
+}
+
+func TestTerminal_watch_idle(t *testing.T) {
+	const frameInterval = time.Second / 20
+
+	clock := newTestClock()
+	counter := newFrameCounter()
+	term := NewTerminal(WithOutput(counter, fixedSize(80, 40)), WithMaxFPS(20), WithClock(clock))
+	stop := startTestWatch(term)
+	defer stop()
+
+	counter.waitFrame(t) // first frame
+
+	// change right after frame is scheduled to next frame
+	_, span := term.StartSpan(context.Background(), "idle")
+	clock.waitTicker(t)
+	assert.Equal(t, int64(1), counter.count())
+
+	clock.advance(frameInterval)
+	counter.waitFrame(t)
+
+	// nothing is changed, so nothing should be rendered
+	clock.advance(time.Second * 10)
+
+	// change after long idle time is rendered right now
+	span.Write("after idle")
+	counter.waitFrame(t)
+	assert.Equal(t, int64(3), counter.count(), "idle frames is rendered")
+
+	// burst of changes is merged into one frame
+	for i := 0; i < 10000; i++ {
+		span.Write(fmt.Sprintf("line %d", i))
+	}
+	clock.waitTicker(t)
+	assert.Equal(t, int64(3), counter.count())
+
+	clock.advance(frameInterval)
+	counter.waitFrame(t)
+	assert.Equal(t, int64(4), counter.count())
+}
+
+func BenchmarkSpan_Write(b *testing.B) {
+	for _, spans := range []int{100, 10000, 50000} {
+		b.Run(fmt.Sprintf("spans=%d", spans), func(b *testing.B) {
+			term := NewTerminal(WithHeadless(), WithOutput(io.Discard, fixedSize(120, 40)))
+			last := fillBenchTree(term, spans)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				last.Write("working")
+			}
+		})
+	}
+}
+
+func BenchmarkTerminal_watch(b *testing.B) {
+	for _, spans := range []int{100, 10000, 50000} {
+		b.Run(fmt.Sprintf("spans=%d", spans), func(b *testing.B) {
+			counter := &frameCounter{}
+			term := NewTerminal(WithOutput(counter, fixedSize(120, 40)))
+			stop := startTestWatch(term)
+			last := fillBenchTree(term, spans)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			frames := counter.count()
+
+			for i := 0; i < b.N; i++ {
+				last.UpdateProgress(float64(i%100) / 100)
+			}
+
+			b.StopTimer()
+			stop()
+			b.ReportMetric(float64(counter.count()-frames)/float64(b.N), "frames/op")
+		})
+	}
+}
+
+// BenchmarkTerminal_watchIdle is cost of time passing without any changes,
+// idle terminal should not render anything
+func BenchmarkTerminal_watchIdle(b *testing.B) {
+	for _, spans := range []int{100, 10000, 50000} {
+		b.Run(fmt.Sprintf("spans=%d", spans), func(b *testing.B) {
+			clock := newTestClock()
+			counter := newFrameCounter()
+			term := NewTerminal(WithOutput(counter, fixedSize(120, 40)), WithClock(clock))
+			fillBenchTree(term, spans)
+			stop := startTestWatch(term)
+			counter.waitFrame(b) // first frame
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			frames := counter.count()
+
+			for i := 0; i < b.N; i++ {
+				clock.advance(time.Second)
+			}
+
+			b.StopTimer()
+			idleFrames := counter.count() - frames
+			stop()
+			b.ReportMetric(float64(idleFrames)/float64(b.N), "frames/op")
+		})
+	}
+}
+
+func BenchmarkTerminal_update(b *testing.B) {
+	for _, spans := range []int{100, 10000, 50000} {
+		b.Run(fmt.Sprintf("spans=%d", spans), func(b *testing.B) {
+			term := NewTerminal(WithOutput(io.Discard, fixedSize(120, 40)))
+			term.setActive(true)
+			term.setRecording(true)
+			fillBenchTree(term, spans)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				term.update()
+			}
+		})
+	}
+}
+
+// fillBenchTree create roots with 100 children each
+// and return latest running span
+func fillBenchTree(term *Terminal, spans int) *Span {
+	const childrenPerRoot = 100
+
+	var ctx context.Context
+	var last *Span
+
+	for i := 0; i < spans; i++ {
+		if i%childrenPerRoot == 0 {
+			ctx, last = term.StartSpan(context.Background(), fmt.Sprintf("root %d", i))
+			continue
+		}
+
+		_, last = term.StartSpan(ctx, fmt.Sprintf("task %d", i))
+		if i%3 == 0 && i != spans-1 {
+			last.End()
+		}
+	}
+
+	return last
+}
+
+func fixedSize(width, height int) func() (int, int) {
+	return func() (int, int) {
+		return width, height
+	}
+}